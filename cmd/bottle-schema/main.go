@@ -0,0 +1,19 @@
+// Package main defines the bottle-schema command to validate, lint, convert and identify bottle metadata files
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/act3-ai/go-common/pkg/runner"
+
+	"github.com/act3-ai/bottle-schema/pkg/cli"
+)
+
+func main() {
+	root := cli.NewRootCmd()
+	ctx := context.Background()
+	root.SetContext(ctx)
+	err := runner.Run(root.Context(), root, "BOTTLE_SCHEMA_VERBOSITY")
+	os.Exit(cli.ExitCode(err))
+}
@@ -2,7 +2,11 @@ package bottle
 
 import (
 	"context"
+	"errors"
+	"io"
+	"io/fs"
 	"testing"
+	"testing/fstest"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go"
@@ -12,7 +16,10 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 
 	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha5"
+	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1beta1"
 	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
 	val "github.com/act3-ai/bottle-schema/pkg/validation"
 )
 
@@ -473,6 +480,226 @@ parts:
 	suite.NoError(bottle.Validate())
 }
 
+func (suite *ConversionTestSuite) TestLoad_Migrate_v1alpha2_Report() {
+	jsonData := `
+	{
+		"apiVersion": "data.act3-ace.io/v1alpha2",
+		"kind": "Bottle",
+		"description": "This is a v1alpha2.",
+		"catalog": true,
+		"keywords": ["dog", "cat"],
+		"files": [
+			{
+				"name": "foo/bar",
+				"size": 45,
+				"format": "tar+gzip",
+				"digest": {"sha256": "9a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}
+			},
+			{
+				"name": "someusage",
+				"size": 450,
+				"digest": {"sha256": "3a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}
+			}
+		]
+	}
+`
+	bottleOriginal, err := runtime.Decode(suite.codecs.UniversalDeserializer(), []byte(jsonData))
+	suite.NoError(err)
+
+	report := &migrate.Report{}
+	bottle := &v1.Bottle{}
+	suite.NoError(suite.scheme.Convert(bottleOriginal, bottle, &migrate.Context{Report: report}))
+	suite.Len(bottle.Parts, 2)
+
+	// every part needs the size and digest recalculated
+	suite.Equal([]int{0, 1}, report.RecomputeIndices("parts"))
+	suite.Empty(report.RecomputeIndices("publicArtifacts"))
+
+	type change struct {
+		Field string
+		Kind  migrate.ChangeKind
+	}
+	changes := make([]change, len(report.Changes))
+	for i, c := range report.Changes {
+		changes[i] = change{c.Field, c.Kind}
+	}
+	suite.Equal([]change{
+		{"parts[0].size", migrate.ChangeRecompute},
+		{"parts[0].digest", migrate.ChangeRecompute},
+		{"parts[1].format", migrate.ChangeDefaulted},
+		{"parts[1].size", migrate.ChangeRecompute},
+		{"parts[1].digest", migrate.ChangeRecompute},
+		{"catalog", migrate.ChangeDropped},
+		{"keywords", migrate.ChangeDropped},
+	}, changes)
+	suite.Equal("data.act3-ace.io/v1alpha2", report.Changes[0].From)
+	suite.Equal("data.act3-ace.io/v1alpha4", report.Changes[0].To)
+}
+
+func (suite *ConversionTestSuite) TestLoad_Migrate_v1alpha2_Recalc() {
+	jsonData := `
+	{
+		"apiVersion": "data.act3-ace.io/v1alpha2",
+		"kind": "Bottle",
+		"description": "This is a v1alpha2.",
+		"files": [
+			{
+				"name": "foo",
+				"size": 45,
+				"format": "tar+gzip",
+				"digest": {"sha256": "9a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}
+			},
+			{
+				"name": "someusage",
+				"size": 450,
+				"digest": {"sha256": "3a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}
+			},
+			{
+				"name": "notpulled",
+				"size": 10,
+				"digest": {"sha256": "4a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}
+			}
+		]
+	}
+`
+	content := &migrate.FSContentProvider{
+		FS: fstest.MapFS{
+			"foo/bar.txt": &fstest.MapFile{Data: []byte("bar")},
+			"someusage":   &fstest.MapFile{Data: []byte("some usage")},
+		},
+		Archive: func(fsys fs.FS, dir string, w io.Writer) error {
+			_, err := io.WriteString(w, "archive of "+dir)
+			return err
+		},
+	}
+
+	bottleOriginal, err := runtime.Decode(suite.codecs.UniversalDeserializer(), []byte(jsonData))
+	suite.NoError(err)
+
+	report := &migrate.Report{}
+	bottle := &v1.Bottle{}
+	suite.NoError(suite.scheme.Convert(bottleOriginal, bottle, &migrate.Context{Report: report, Content: content}))
+	suite.Equal(int64(len("archive of foo")), bottle.Parts[0].Size)
+	suite.Equal(digest.FromString("archive of foo"), bottle.Parts[0].Digest)
+	suite.Equal(int64(len("some usage")), bottle.Parts[1].Size)
+	suite.Equal(digest.FromString("some usage"), bottle.Parts[1].Digest)
+
+	// parts that are not available locally still need to be recomputed
+	suite.Equal([]int{2}, report.RecomputeIndices("parts"))
+
+	// once every part is available the bottle is valid
+	bottle.Parts = bottle.Parts[:2]
+	suite.NoError(bottle.Validate())
+
+	// other errors are not ignored
+	failing := migrate.PartContentFunc(func(name string) (int64, digest.Digest, error) {
+		return 0, "", errors.New("disk on fire")
+	})
+	suite.ErrorContains(suite.scheme.Convert(bottleOriginal, &v1.Bottle{}, &migrate.Context{Content: failing}), "disk on fire")
+}
+
+func (suite *ConversionTestSuite) downgradeTestBottle() (*v1.Bottle, *ocispecv1.Manifest) {
+	bottle := v1.NewBottle()
+	bottle.Description = "Going down"
+	bottle.Annotations = map[string]string{"note": "keep me"}
+	bottle.Sources = []v1.Source{{Name: "Training", URI: "https://data.example.com"}}
+	bottle.Authors = []v1.Author{{Name: "Jane Smith", Email: "jane.smith@example.com"}}
+	bottle.Metrics = []v1.Metric{{Name: "AUC", Value: "0.985"}}
+	bottle.PublicArtifacts = []v1.PublicArtifact{
+		{Name: "Some text", Path: "sample.txt", MediaType: "text/plain; charset=utf-8", Digest: "sha256:eab4fe92c4c81e25676d91b3dac3191fe3d0a22e2a6644b76726a7683862a339"},
+		{Name: "Notes", Path: "data/notes.md", MediaType: "text/x-custom", Digest: "sha256:fab4fe92c4c81e25676d91b3dac3191fe3d0a22e2a6644b76726a7683862a339"},
+	}
+	bottle.Deprecates = []digest.Digest{
+		"sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9",
+		"sha256:2dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9",
+	}
+	bottle.Parts = []v1.Part{
+		{Name: "data/", Size: 2048, Digest: "sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"},
+		{Name: "sample.txt", Size: 450, Digest: "sha256:0a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae", Labels: map[string]string{"a": "b"}},
+	}
+
+	manifest := &ocispecv1.Manifest{
+		Versioned: ocispec.Versioned{SchemaVersion: 2},
+		MediaType: ocispecv1.MediaTypeImageManifest,
+		Config: ocispecv1.Descriptor{
+			MediaType: mediatype.MediaTypeBottleConfig,
+			Digest:    digest.FromString("config"),
+			Size:      6,
+		},
+		Layers: []ocispecv1.Descriptor{
+			{MediaType: mediatype.MediaTypeLayerTarGzip, Digest: digest.FromString("layer0"), Size: 500},
+			{MediaType: mediatype.MediaTypeLayer, Digest: digest.FromString("layer1"), Size: 450},
+		},
+	}
+	return &bottle, manifest
+}
+
+func (suite *ConversionTestSuite) TestDowngrade_v1beta1() {
+	bottle, manifest := suite.downgradeTestBottle()
+
+	report := &migrate.Report{}
+	old := &v1beta1.Bottle{}
+	suite.NoError(suite.scheme.Convert(bottle, old, &migrate.Context{Report: report}))
+
+	suite.Equal(v1beta1.GroupVersion.WithKind("Bottle"), old.GroupVersionKind())
+	suite.Equal("sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9,sha256:2dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9",
+		old.Annotations[v1beta1.AnnotationDeprecates])
+	suite.Equal("keep me", old.Annotations["note"])
+	suite.NotContains(bottle.Annotations, v1beta1.AnnotationDeprecates, "the input must not be modified")
+	suite.Equal("data", old.Parts[0].Name)
+	suite.Equal("sample.txt", old.Parts[1].Name)
+	suite.NoError(old.Validate())
+
+	suite.Equal([]migrate.Change{
+		{Field: "parts[0].name", Kind: migrate.ChangeDropped, From: "data.act3-ace.io/v1", To: "data.act3-ace.io/v1beta1",
+			Message: `trailing slash removed from directory part "data/" (v1beta1 relies on the layer media type)`},
+	}, report.Changes)
+
+	// and back up again with the manifest is lossless
+	upgraded := &v1.Bottle{}
+	suite.NoError(suite.scheme.Convert(old, upgraded, manifest))
+	suite.Equal(bottle, upgraded)
+}
+
+func (suite *ConversionTestSuite) TestDowngrade_v1beta1_MergeDeprecates() {
+	bottle, _ := suite.downgradeTestBottle()
+	// the annotation is already set (with one of the deprecated bottles)
+	bottle.Annotations[v1beta1.AnnotationDeprecates] = "sha256:1dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9," +
+		"sha256:2dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9"
+
+	old := &v1beta1.Bottle{}
+	suite.NoError(suite.scheme.Convert(bottle, old, &migrate.Context{Report: &migrate.Report{}}))
+	suite.Equal("sha256:1dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9,"+
+		"sha256:2dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9,"+
+		"sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9",
+		old.Annotations[v1beta1.AnnotationDeprecates])
+}
+
+func (suite *ConversionTestSuite) TestDowngrade_v1alpha5() {
+	bottle, manifest := suite.downgradeTestBottle()
+
+	report := &migrate.Report{}
+	old := &v1alpha5.Bottle{}
+	suite.NoError(suite.scheme.Convert(bottle, old, &migrate.Context{Manifest: manifest, Report: report}))
+
+	suite.Equal(v1alpha5.GroupVersion.WithKind("Bottle"), old.GroupVersionKind())
+	suite.Equal("https://data.example.com", old.Sources[0].URL)
+	suite.Equal("data", old.Parts[0].Name)
+	suite.Equal(int64(500), old.Parts[0].LayerSize)
+	suite.Equal(manifest.Layers[0].Digest.String(), old.Parts[0].LayerDigest)
+	suite.Contains(old.Annotations, v1beta1.AnnotationDeprecates)
+
+	fields := make([]string, len(report.Changes))
+	for i, c := range report.Changes {
+		fields[i] = c.Field
+	}
+	// sample.txt has the media type derived from the path so it is not lossy
+	suite.Equal([]string{"parts[0].name", "publicArtifacts[1].mediaType"}, fields)
+
+	// no report is fine too
+	suite.NoError(suite.scheme.Convert(bottle, &v1alpha5.Bottle{}, nil))
+}
+
 func TestConversionTestSuite(t *testing.T) {
 	suite.Run(t, new(ConversionTestSuite))
 }
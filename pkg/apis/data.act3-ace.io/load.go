@@ -0,0 +1,137 @@
+package bottle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// LoadOptions are the options for Load
+type LoadOptions struct {
+	// Manifest of the bottle (optional).
+	// It is used by the conversion to determine which parts are directories and, with Validate, to validate the bottle
+	// against the manifest.  Loading fails if a layer has no media type or, for versions before v1, if the number of
+	// layers differs from the number of parts.
+	Manifest *ocispecv1.Manifest
+
+	// Content is used to recompute the part sizes and digests that older versions did not record (optional)
+	Content migrate.PartContentProvider
+
+	// Validate the bottle after it is converted and defaulted
+	Validate bool
+
+	// Strict rejects documents with unknown fields, values of the wrong type or duplicate keys (see CheckStrict).
+	// The error wraps DecodeErrors that locate each problem in the document.
+	Strict bool
+}
+
+// LoadResult is a bottle document loaded by Load
+type LoadResult struct {
+	// Bottle converted to v1 (with defaults applied)
+	Bottle *v1.Bottle
+
+	// Original is the decoded document in its own version
+	Original runtime.Object
+
+	// OriginalVersion is the API version of the document
+	OriginalVersion schema.GroupVersion
+
+	// Changes made by the conversion to v1 that lost information (empty for v1 documents)
+	Changes []migrate.Change
+
+	// Validation is the validation report of the bottle (nil unless LoadOptions.Validate is set)
+	Validation *val.Report
+}
+
+// scheme has every version of the bottle
+var scheme = sync.OnceValues(func() (*runtime.Scheme, error) {
+	s := runtime.NewScheme()
+	if err := AddToScheme(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+})
+
+// Scheme returns a scheme with every version of the bottle registered (including the conversion and defaulting
+// functions).  The scheme is shared so it must not be modified.
+func Scheme() (*runtime.Scheme, error) {
+	return scheme()
+}
+
+// Load reads a bottle document (JSON or YAML) of any version, converts it to v1 and applies the defaults.
+// The API version is detected from the document's apiVersion and kind.
+// If opts.Validate is set the bottle is validated and, when it is invalid, the result is returned along with an error
+// wrapping the validation report's errors.
+func Load(r io.Reader, opts LoadOptions) (*LoadResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading bottle: %w", err)
+	}
+	if opts.Strict {
+		if err := CheckStrict(data); err != nil {
+			return nil, fmt.Errorf("decoding bottle: %w", err)
+		}
+	}
+	s, err := scheme()
+	if err != nil {
+		return nil, err
+	}
+	obj, gvk, err := serializer.NewCodecFactory(s).UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decoding bottle: %w", err)
+	}
+
+	if err := checkLayerMediaTypes(opts.Manifest); err != nil {
+		return nil, err
+	}
+
+	report := &migrate.Report{}
+	b := &v1.Bottle{}
+	if err := s.Convert(obj, b, &migrate.Context{Manifest: opts.Manifest, Report: report, Content: opts.Content}); err != nil {
+		return nil, fmt.Errorf("converting bottle from %s to %s: %w", gvk.GroupVersion(), v1.GroupVersion, err)
+	}
+	s.Default(b)
+
+	result := &LoadResult{
+		Bottle:          b,
+		Original:        obj,
+		OriginalVersion: gvk.GroupVersion(),
+		Changes:         report.Changes,
+	}
+	if !opts.Validate {
+		return result, nil
+	}
+	ctx := context.Background()
+	if opts.Manifest != nil {
+		ctx = val.ContextWithManifest(ctx, opts.Manifest)
+	}
+	result.Validation = b.ValidationReport(ctx)
+	if err := result.Validation.Err(); err != nil {
+		return result, fmt.Errorf("invalid bottle: %w", err)
+	}
+	return result, nil
+}
+
+// checkLayerMediaTypes ensures every layer of the manifest (if any) has a media type since the conversion and the
+// validation use it to determine which parts are directories
+func checkLayerMediaTypes(manifest *ocispecv1.Manifest) error {
+	if manifest == nil {
+		return nil
+	}
+	for i, desc := range manifest.Layers {
+		if desc.MediaType == "" {
+			return fmt.Errorf("layer %d of the manifest has no media type", i)
+		}
+	}
+	return nil
+}
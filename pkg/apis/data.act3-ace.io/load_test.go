@@ -0,0 +1,119 @@
+package bottle
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha2"
+	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha5"
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+func (suite *ConversionTestSuite) TestLoad() {
+	// YAML of an old version
+	result, err := Load(strings.NewReader(`
+apiVersion: data.act3-ace.io/v1alpha5
+kind: Bottle
+description: MNIST Dataset
+parts:
+  - name: foo
+    size: 45
+    digest: sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae
+`), LoadOptions{Validate: true})
+	suite.NoError(err)
+	suite.Equal(v1alpha5.GroupVersion, result.OriginalVersion)
+	suite.IsType(&v1alpha5.Bottle{}, result.Original)
+	suite.Equal(v1.GroupVersion.WithKind("Bottle"), result.Bottle.GroupVersionKind())
+	suite.Equal("MNIST Dataset", result.Bottle.Description)
+	suite.False(result.Validation.HasErrors())
+
+	// JSON of the oldest version with the content to recompute the part
+	result, err = Load(strings.NewReader(`{
+		"apiVersion": "data.act3-ace.io/v1alpha2",
+		"kind": "Bottle",
+		"files": [{"name": "foo", "size": 45, "digest": {"sha256": "9a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}}]
+	}`), LoadOptions{
+		Content: migrate.PartContentFunc(func(name string) (int64, digest.Digest, error) {
+			return 100, digest.FromString(name), nil
+		}),
+	})
+	suite.NoError(err)
+	suite.Equal(v1alpha2.GroupVersion, result.OriginalVersion)
+	suite.Equal(int64(100), result.Bottle.Parts[0].Size)
+	suite.Equal(digest.FromString("foo"), result.Bottle.Parts[0].Digest)
+	suite.Nil(result.Validation)
+
+	// validated against the manifest
+	manifest := &ocispecv1.Manifest{
+		Versioned: ocispec.Versioned{SchemaVersion: 2},
+		MediaType: ocispecv1.MediaTypeImageManifest,
+		Config: ocispecv1.Descriptor{
+			MediaType: mediatype.MediaTypeBottleConfig,
+			Digest:    digest.FromString("config"),
+			Size:      6,
+		},
+	}
+	result, err = Load(strings.NewReader(`{
+		"apiVersion": "data.act3-ace.io/v1",
+		"kind": "Bottle",
+		"parts": [{"name": "foo", "size": 45, "digest": "sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}]
+	}`), LoadOptions{Manifest: manifest, Validate: true})
+	suite.ErrorContains(err, "invalid bottle")
+	suite.Equal(v1.GroupVersion, result.OriginalVersion)
+	suite.Contains(codes(result.Validation.Issues), val.CodeLayerCount)
+
+	// not a bottle
+	_, err = Load(strings.NewReader(`{"apiVersion": "v1", "kind": "Pod"}`), LoadOptions{})
+	suite.ErrorContains(err, "decoding bottle")
+	_, err = Load(strings.NewReader(`{]`), LoadOptions{})
+	suite.Error(err)
+}
+
+func codes(issues val.IssueList) []string {
+	c := make([]string, len(issues))
+	for i, issue := range issues {
+		c[i] = issue.Code
+	}
+	return c
+}
+
+func (suite *ConversionTestSuite) TestLoad_MismatchedManifest() {
+	doc := []byte(`apiVersion: data.act3-ace.io/v1beta1
+kind: Bottle
+parts:
+  - name: data
+    size: 1
+    digest: sha256:9fdb955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c0
+  - name: model.onnx
+    size: 1
+    digest: sha256:9fdb955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c1
+`)
+	layer := ocispecv1.Descriptor{
+		MediaType: mediatype.MediaTypeLayerTarGzip,
+		Digest:    digest.FromString("data"),
+		Size:      1,
+	}
+
+	// fewer layers than parts
+	_, err := Load(bytes.NewReader(doc), LoadOptions{
+		Manifest: &ocispecv1.Manifest{Layers: []ocispecv1.Descriptor{layer}},
+	})
+	suite.ErrorContains(err, "the manifest has 1 layers but the bottle has 2 parts")
+
+	// a layer without a media type
+	noMediaType := layer
+	noMediaType.MediaType = ""
+	_, err = Load(bytes.NewReader(doc), LoadOptions{
+		Manifest: &ocispecv1.Manifest{Layers: []ocispecv1.Descriptor{layer, noMediaType}},
+		Validate: true,
+	})
+	suite.ErrorContains(err, "layer 1 of the manifest has no media type")
+
+}
@@ -0,0 +1,392 @@
+package bottle
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	yamlv2 "sigs.k8s.io/yaml/goyaml.v2"
+)
+
+// DecodeErrorType categorizes the problems found by strict decoding
+type DecodeErrorType string
+
+const (
+	// DecodeSyntax is used when the document is not valid JSON or YAML
+	DecodeSyntax DecodeErrorType = "syntax"
+
+	// DecodeUnknownField is used when a field is not defined by the document's API version
+	DecodeUnknownField DecodeErrorType = "unknown_field"
+
+	// DecodeWrongType is used when a value does not have the type of its field (e.g., a list instead of a string)
+	DecodeWrongType DecodeErrorType = "wrong_type"
+
+	// DecodeDuplicateKey is used when a key appears more than once in the same object
+	DecodeDuplicateKey DecodeErrorType = "duplicate_key"
+)
+
+// DecodeError is a problem in a bottle document found by strict decoding.
+// Line and Column (starting at 1) locate the problem in the source document (JSON or YAML).
+type DecodeError struct {
+	// Type of problem
+	Type DecodeErrorType `json:"type"`
+
+	// Line of the offending key or value (0 if unknown)
+	Line int `json:"line,omitempty"`
+
+	// Column of the offending key or value (0 if unknown)
+	Column int `json:"column,omitempty"`
+
+	// Field is the path to the offending field (e.g., "parts[0].mediatype")
+	Field string `json:"field,omitempty"`
+
+	// Message is the human readable description of the problem
+	Message string `json:"message"`
+
+	// Suggestion is the known field name that a misspelled field was most likely meant to be (if any)
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	var sb strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "line %d, column %d: ", e.Line, e.Column)
+	}
+	if e.Field != "" {
+		sb.WriteString(e.Field)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Message)
+	if e.Suggestion != "" {
+		fmt.Fprintf(&sb, " (did you mean %q?)", e.Suggestion)
+	}
+	return sb.String()
+}
+
+// DecodeErrors is the list of problems found by strict decoding
+type DecodeErrors []*DecodeError
+
+// Error implements the error interface
+func (l DecodeErrors) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// CheckStrict checks a bottle document (JSON or YAML) against the types of its API version.
+// Unlike the decoder, it finds every unknown field (with a suggestion for misspelled field names), value of the wrong
+// type and duplicate key, and locates each of them by line and column.  Scalars are typed with the YAML 1.1 rules of
+// the decoder (e.g., "yes" and "on" are booleans).
+// It returns DecodeErrors if there are any problems.  Documents with an unknown apiVersion or kind are not checked
+// (decoding them fails anyway).
+func CheckStrict(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return DecodeErrors{{Type: DecodeSyntax, Message: err.Error()}}
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+
+	var apiVersion, kind string
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			switch root.Content[i].Value {
+			case "apiVersion":
+				apiVersion = root.Content[i+1].Value
+			case "kind":
+				kind = root.Content[i+1].Value
+			}
+		}
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil
+	}
+	s, err := scheme()
+	if err != nil {
+		return err
+	}
+	obj, err := s.New(gv.WithKind(kind))
+	if err != nil {
+		return nil
+	}
+
+	c := &strictChecker{}
+	c.check(nil, root, reflect.TypeOf(obj))
+	if len(c.errs) > 0 {
+		return c.errs
+	}
+	return nil
+}
+
+// strictChecker walks a YAML node tree along with the Go type it is decoded into
+type strictChecker struct {
+	errs DecodeErrors
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+func (c *strictChecker) add(typ DecodeErrorType, fldPath *field.Path, n *yaml.Node, message string) *DecodeError {
+	e := &DecodeError{
+		Type:    typ,
+		Line:    n.Line,
+		Column:  n.Column,
+		Message: message,
+	}
+	if fldPath != nil {
+		e.Field = fldPath.String()
+	}
+	c.errs = append(c.errs, e)
+	return e
+}
+
+func (c *strictChecker) check(fldPath *field.Path, n *yaml.Node, t reflect.Type) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		// the type decodes itself (e.g., metav1.Time) so anything could be valid
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !c.expect(fldPath, n, yaml.MappingNode, "an object") {
+			return
+		}
+		fields := jsonFields(t)
+		c.checkMapping(fldPath, n, childPath, func(keyPath *field.Path, key, value *yaml.Node) {
+			ft, ok := fields[key.Value]
+			if !ok {
+				e := c.add(DecodeUnknownField, keyPath, key, "unknown field")
+				e.Suggestion = suggest(key.Value, fields)
+				return
+			}
+			c.check(keyPath, value, ft)
+		})
+	case reflect.Map:
+		if !c.expect(fldPath, n, yaml.MappingNode, "an object") {
+			return
+		}
+		c.checkMapping(fldPath, n, mapKeyPath, func(keyPath *field.Path, _, value *yaml.Node) {
+			c.check(keyPath, value, t.Elem())
+		})
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			c.expectScalar(fldPath, n, "a string", "!!str")
+			return
+		}
+		if !c.expect(fldPath, n, yaml.SequenceNode, "a list") {
+			return
+		}
+		for i, item := range n.Content {
+			c.check(fldPath.Index(i), item, t.Elem())
+		}
+	case reflect.String:
+		// unquoted dates are strings once converted to JSON
+		c.expectScalar(fldPath, n, "a string", "!!str", "!!timestamp")
+	case reflect.Bool:
+		c.expectScalar(fldPath, n, "a boolean", "!!bool")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		c.expectScalar(fldPath, n, "an integer", "!!int")
+	case reflect.Float32, reflect.Float64:
+		c.expectScalar(fldPath, n, "a number", "!!int", "!!float")
+	}
+}
+
+// checkMapping checks for duplicate keys and calls fn for each key and value of the mapping node.
+// path returns the path of the value of a key.
+func (c *strictChecker) checkMapping(fldPath *field.Path, n *yaml.Node, path func(*field.Path, string) *field.Path,
+	fn func(keyPath *field.Path, key, value *yaml.Node),
+) {
+	seen := map[string]int{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		keyPath := path(fldPath, key.Value)
+		if line, ok := seen[key.Value]; ok {
+			c.add(DecodeDuplicateKey, keyPath, key, fmt.Sprintf("duplicate key (already defined at line %d)", line))
+			continue
+		}
+		seen[key.Value] = key.Line
+		fn(keyPath, key, value)
+	}
+}
+
+// expect adds an error if the node is not of the kind
+func (c *strictChecker) expect(fldPath *field.Path, n *yaml.Node, kind yaml.Kind, want string) bool {
+	if n.Kind == kind {
+		return true
+	}
+	c.add(DecodeWrongType, fldPath, n, fmt.Sprintf("expected %s but found %s", want, describe(n)))
+	return false
+}
+
+// expectScalar adds an error if the node is not a scalar with one of the tags
+func (c *strictChecker) expectScalar(fldPath *field.Path, n *yaml.Node, want string, tags ...string) {
+	if n.Kind == yaml.ScalarNode {
+		for _, tag := range tags {
+			if scalarTag(n) == tag {
+				return
+			}
+		}
+	}
+	msg := fmt.Sprintf("expected %s but found %s", want, describe(n))
+	if n.Kind == yaml.ScalarNode && tags[0] == "!!str" {
+		msg += fmt.Sprintf(" (quote the value: %q)", n.Value)
+	}
+	c.add(DecodeWrongType, fldPath, n, msg)
+}
+
+// describe returns the type of the node for error messages
+func describe(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch scalarTag(n) {
+	case "!!bool":
+		return "a boolean"
+	case "!!int":
+		return "an integer"
+	case "!!float":
+		return "a number"
+	case "!!str":
+		return "a string"
+	}
+	return n.ShortTag()
+}
+
+// scalarTag returns the tag of the scalar node as resolved by the decoder.
+// The decoder (sigs.k8s.io/yaml) follows YAML 1.1 where plain scalars such as "yes", "on" and "0b101" are booleans and
+// integers while yaml.v3 (YAML 1.2) resolves them as strings.
+func scalarTag(n *yaml.Node) string {
+	if n.Kind != yaml.ScalarNode || n.Style != 0 {
+		// quoted, block and explicitly tagged scalars are resolved the same way by both
+		return n.ShortTag()
+	}
+	var v any
+	if err := yamlv2.Unmarshal([]byte(n.Value), &v); err != nil {
+		return n.ShortTag()
+	}
+	switch v.(type) {
+	case nil:
+		return "!!null"
+	case bool:
+		return "!!bool"
+	case int, int64, uint64:
+		return "!!int"
+	case float64:
+		return "!!float"
+	case string:
+		return "!!str"
+	}
+	return n.ShortTag()
+}
+
+// childPath returns the path of the map key or struct field
+func childPath(fldPath *field.Path, name string) *field.Path {
+	if fldPath == nil {
+		return field.NewPath(name)
+	}
+	return fldPath.Child(name)
+}
+
+// mapKeyPath returns the path of the map key
+func mapKeyPath(fldPath *field.Path, key string) *field.Path {
+	if fldPath == nil {
+		return field.NewPath("").Key(key)
+	}
+	return fldPath.Key(key)
+}
+
+// jsonFields returns the type of each field of the struct by JSON name (including the fields of inlined structs)
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					fields[k] = v
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// suggest returns the known field name closest to the unknown name (or "" if none is close enough).
+// Names are compared ignoring case so a field with the wrong case (e.g., "mediatype") is always matched.
+func suggest(name string, fields map[string]reflect.Type) string {
+	limit := max(2, len(name)/3)
+	best, bestDistance := "", limit+1
+	for known := range fields {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(known))
+		if d < bestDistance || d == bestDistance && known < best {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
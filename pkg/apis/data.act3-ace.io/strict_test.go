@@ -0,0 +1,104 @@
+package bottle
+
+import (
+	"errors"
+	"strings"
+)
+
+func (suite *ConversionTestSuite) TestCheckStrict() {
+	err := CheckStrict([]byte(`apiVersion: data.act3-ace.io/v1
+kind: Bottle
+description: MNIST
+labels:
+  type: testing
+  type: training
+publicArtifact:
+  - name: foo
+metrics:
+  - name: accuracy
+    value: 0.98
+publicArtifacts:
+  - name: Some text
+    mediatype: text/plain
+    path: [sample.txt]
+parts:
+  - name: foo
+    size: big
+    digest: sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae
+`))
+	var errs DecodeErrors
+	suite.Require().True(errors.As(err, &errs))
+	suite.Equal(DecodeErrors{
+		{Type: DecodeDuplicateKey, Line: 6, Column: 3, Field: "labels[type]", Message: "duplicate key (already defined at line 5)"},
+		{Type: DecodeUnknownField, Line: 7, Column: 1, Field: "publicArtifact", Message: "unknown field", Suggestion: "publicArtifacts"},
+		{Type: DecodeWrongType, Line: 11, Column: 12, Field: "metrics[0].value", Message: `expected a string but found a number (quote the value: "0.98")`},
+		{Type: DecodeUnknownField, Line: 14, Column: 5, Field: "publicArtifacts[0].mediatype", Message: "unknown field", Suggestion: "mediaType"},
+		{Type: DecodeWrongType, Line: 15, Column: 11, Field: "publicArtifacts[0].path", Message: "expected a string but found a list"},
+		{Type: DecodeWrongType, Line: 18, Column: 11, Field: "parts[0].size", Message: "expected an integer but found a string"},
+	}, errs)
+	suite.Equal(`line 7, column 1: publicArtifact: unknown field (did you mean "publicArtifacts"?)`, errs[1].Error())
+
+	// JSON is located too and unrelated names have no suggestion
+	err = CheckStrict([]byte(`{
+  "apiVersion": "data.act3-ace.io/v1beta1",
+  "kind": "Bottle",
+  "colour": "blue"
+}`))
+	suite.EqualError(err, `line 4, column 3: colour: unknown field`)
+
+	// each version is checked against its own types
+	suite.NoError(CheckStrict([]byte(`{"apiVersion": "data.act3-ace.io/v1alpha2", "kind": "Bottle", "maintainers": [{"name": "Jane"}], "files": [{"name": "foo", "digest": {"sha256": "abc"}}]}`)))
+	suite.ErrorContains(CheckStrict([]byte(`{"apiVersion": "data.act3-ace.io/v1", "kind": "Bottle", "maintainers": []}`)), "maintainers: unknown field")
+
+	// unknown versions are left to the decoder
+	suite.NoError(CheckStrict([]byte(`{"apiVersion": "data.act3-ace.io/v9", "kind": "Bottle", "foo": 1}`)))
+
+	err = CheckStrict([]byte("apiVersion: [\n"))
+	suite.Require().True(errors.As(err, &errs))
+	suite.Equal(DecodeSyntax, errs[0].Type)
+}
+
+func (suite *ConversionTestSuite) TestLoad_Strict() {
+	doc := `apiVersion: data.act3-ace.io/v1
+kind: Bottle
+descripton: MNIST
+`
+	result, err := Load(strings.NewReader(doc), LoadOptions{})
+	suite.NoError(err)
+	suite.Empty(result.Bottle.Description)
+
+	_, err = Load(strings.NewReader(doc), LoadOptions{Strict: true})
+	var errs DecodeErrors
+	suite.Require().True(errors.As(err, &errs))
+	suite.Equal("description", errs[0].Suggestion)
+	suite.Equal(3, errs[0].Line)
+}
+
+func (suite *ConversionTestSuite) TestLoad_StrictYAML11() {
+	// the decoder follows YAML 1.1 so "yes" is a boolean
+	doc := `apiVersion: data.act3-ace.io/v1alpha4
+kind: Bottle
+description: catalogued
+catalog: yes
+`
+	result, err := Load(strings.NewReader(doc), LoadOptions{})
+	suite.Require().NoError(err)
+	suite.NoError(CheckStrict([]byte(doc)))
+	strict, err := Load(strings.NewReader(doc), LoadOptions{Strict: true})
+	suite.Require().NoError(err)
+	suite.Equal(result.Bottle, strict.Bottle)
+
+	// and "on" is not a string
+	doc = `apiVersion: data.act3-ace.io/v1
+kind: Bottle
+description: on
+`
+	err = CheckStrict([]byte(doc))
+	var errs DecodeErrors
+	suite.Require().True(errors.As(err, &errs))
+	suite.Equal(DecodeErrors{
+		{Type: DecodeWrongType, Line: 3, Column: 14, Field: "description", Message: `expected a string but found a boolean (quote the value: "on")`},
+	}, errs)
+	_, err = Load(strings.NewReader(doc), LoadOptions{})
+	suite.Error(err, "the decoder rejects it too")
+}
@@ -0,0 +1,29 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/act3-ai/bottle-schema/pkg/util"
+)
+
+// CanonicalJSON returns the canonical JSON encoding of the bottle.
+// Keys are sorted, there is no insignificant whitespace, and map ordering is deterministic so identical metadata
+// always produces identical bytes.  This is the encoding that should be used for the bottle config.
+func (b Bottle) CanonicalJSON() ([]byte, error) {
+	return util.CanonicalJSON(b)
+}
+
+// BottleID returns the bottle ID (the digest of the canonical bottle config) using the given digest algorithm.
+func (b Bottle) BottleID(alg digest.Algorithm) (digest.Digest, error) {
+	if !alg.Available() {
+		return "", fmt.Errorf("digest algorithm %q is not available", alg)
+	}
+
+	data, err := b.CanonicalJSON()
+	if err != nil {
+		return "", fmt.Errorf("encoding bottle config: %w", err)
+	}
+	return alg.FromBytes(data), nil
+}
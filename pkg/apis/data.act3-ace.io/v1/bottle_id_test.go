@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBottle_CanonicalJSON(t *testing.T) {
+	assert := assert.New(t)
+	bottle := testBottle()
+
+	out, err := bottle.CanonicalJSON()
+	assert.NoError(err)
+
+	// keys are sorted at every level and there is no whitespace
+	expected := `{"apiVersion":"data.act3-ace.io/v1","deprecates":["sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9","sha256:2dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9"],"description":"My bottle name\nMy cool bottle is so neat!","kind":"Bottle","labels":{"a":"b","mykey":"myvalue"},"parts":[{"digest":"sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c0","labels":{"key":"value"},"name":"file.txt","size":45}],"publicArtifacts":[{"digest":"sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c0","mediaType":"text/plain","name":"some file","path":"file.txt"}],"sources":[{"name":"Original data","uri":"https://mydataset.example.com"}]}`
+	assert.Equal(expected, string(out))
+
+	// empty and nil collections encode the same
+	bottle.Annotations = map[string]string{}
+	bottle.Authors = []Author{}
+	out2, err := bottle.CanonicalJSON()
+	assert.NoError(err)
+	assert.Equal(out, out2)
+}
+
+func TestBottle_BottleID(t *testing.T) {
+	tests := []struct {
+		name   string
+		bottle Bottle
+		alg    digest.Algorithm
+		want   digest.Digest
+	}{
+		{"new sha256", NewBottle(), digest.SHA256, "sha256:6028fff46ceaf2f006bd1644340214fbf319b689b5f9a4ff32975a2d4e60312c"},
+		{"full sha256", *testBottle(), digest.SHA256, "sha256:335b53d1bca03e39df6f5075035a63f6181d09c59f81b5a9ab0b01063f74cd48"},
+		{"full sha512", *testBottle(), digest.SHA512, "sha512:9c748cc241e89e55e3c578522ef704a409060a51ef26a588a02c0f7f7a4abc676224d3a998ef128e65a334232248a758ec32525dec93c842f000c0ca2efdb9fb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.bottle.BottleID(tt.alg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := NewBottle().BottleID(digest.Algorithm("md5"))
+	assert.EqualError(t, err, `digest algorithm "md5" is not available`)
+}
@@ -2,11 +2,12 @@ package v1
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/opencontainers/go-digest"
-	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha2"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha3"
@@ -14,6 +15,7 @@ import (
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha5"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1beta1"
 	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
 )
 
 // SetDefault_Bottle sets the fields not already set to default values
@@ -71,15 +73,21 @@ func Convert_v1beta1_Bottle_To_v1_Bottle(in *v1beta1.Bottle, out *Bottle, scope
 		delete(out.Annotations, v1beta1.AnnotationDeprecates)
 	}
 
-	manifest, haveManifest := scope.Meta().Context.(*ocispecv1.Manifest)
+	manifest, _ := migrate.FromScope(scope)
+	if manifest != nil && len(manifest.Layers) != len(in.Parts) {
+		return fmt.Errorf("the manifest has %d layers but the bottle has %d parts", len(manifest.Layers), len(in.Parts))
+	}
 
 	// migrate parts -> stays the same
 	out.Parts = make([]Part, len(in.Parts))
 	for i, f := range in.Parts {
 		out.Parts[i] = Part(f)
-		if haveManifest {
+		if manifest != nil {
 			// use the manifest to handle the conversion for ensuring directory parts have a trailing slash
 			desc := manifest.Layers[i]
+			if desc.MediaType == "" {
+				return fmt.Errorf("layer %d of the manifest has no media type", i)
+			}
 			if mediatype.IsArchived(desc.MediaType) {
 				// require a trailing slash
 				if !strings.HasSuffix(out.Parts[i].Name, "/") {
@@ -131,3 +139,82 @@ func Convert_v1alpha2_Bottle_To_v1_Bottle(in *v1alpha2.Bottle, out *Bottle, scop
 	}
 	return scope.Convert(a4, out)
 }
+
+// Convert_v1_Bottle_To_v1beta1_Bottle converts Bottle from v1 down to v1beta1 (for tools that only understand v1beta1)
+func Convert_v1_Bottle_To_v1beta1_Bottle(in *Bottle, out *v1beta1.Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	_, report := migrate.FromScope(scope)
+	from, to := GroupVersion.String(), v1beta1.GroupVersion.String()
+
+	out.APIVersion = to
+	out.Kind = "Bottle"
+
+	// No root level migrations (copy the annotations since we might add one)
+	out.Labels = in.Labels
+	out.Description = in.Description
+	if in.Annotations != nil || len(in.Deprecates) > 0 {
+		out.Annotations = make(map[string]string, len(in.Annotations)+1)
+		for k, v := range in.Annotations {
+			out.Annotations[k] = v
+		}
+	}
+
+	// migrate sources -> stays the same
+	out.Sources = make([]v1beta1.Source, len(in.Sources))
+	for i, s := range in.Sources {
+		out.Sources[i] = v1beta1.Source(s)
+	}
+
+	// migrate authors -> stays the same
+	out.Authors = make([]v1beta1.Author, len(in.Authors))
+	for i, a := range in.Authors {
+		out.Authors[i] = v1beta1.Author(a)
+	}
+
+	// migrate metrics -> stays the same
+	out.Metrics = make([]v1beta1.Metric, len(in.Metrics))
+	for i, m := range in.Metrics {
+		out.Metrics[i] = v1beta1.Metric(m)
+	}
+
+	// migrate public artifact -> stays the same
+	out.PublicArtifacts = make([]v1beta1.PublicArtifact, len(in.PublicArtifacts))
+	for i, art := range in.PublicArtifacts {
+		out.PublicArtifacts[i] = v1beta1.PublicArtifact(art)
+	}
+
+	// migrate deprecates move from the field back to the annotation (merged with the annotation if it is already set)
+	if len(in.Deprecates) > 0 {
+		var deprecated []string
+		if existing := out.Annotations[v1beta1.AnnotationDeprecates]; existing != "" {
+			deprecated = strings.Split(existing, ",")
+		}
+		for _, d := range in.Deprecates {
+			if !slices.Contains(deprecated, d.String()) {
+				deprecated = append(deprecated, d.String())
+			}
+		}
+		out.Annotations[v1beta1.AnnotationDeprecates] = strings.Join(deprecated, ",")
+	}
+
+	// migrate parts, v1beta1 does not use trailing slashes (the manifest's layer media type identifies directories)
+	out.Parts = make([]v1beta1.Part, len(in.Parts))
+	for i, p := range in.Parts {
+		out.Parts[i] = v1beta1.Part(p)
+		if strings.HasSuffix(p.Name, "/") {
+			out.Parts[i].Name = strings.TrimSuffix(p.Name, "/")
+			report.Dropped(field.NewPath("parts").Index(i).Child("name"), from, to,
+				fmt.Sprintf("trailing slash removed from directory part %q (v1beta1 relies on the layer media type)", p.Name))
+		}
+	}
+
+	return nil
+}
+
+// Convert_v1_Bottle_To_v1alpha5_Bottle converts Bottle from v1 down to v1alpha5
+func Convert_v1_Bottle_To_v1alpha5_Bottle(in *Bottle, out *v1alpha5.Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	b1 := &v1beta1.Bottle{}
+	if err := scope.Convert(in, b1); err != nil {
+		return err
+	}
+	return scope.Convert(b1, out)
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
+	"github.com/act3-ai/bottle-schema/pkg/util"
+)
+
+// DirectoryOptions are the options for FromDirectory
+type DirectoryOptions struct {
+	// Exclude returns true if the top-level entry should not be a part (optional).
+	// Hidden entries (starting with ".") are always excluded since they are not portable (this is where tools keep
+	// the bottle's metadata).  Excluded entries are not checked (e.g., an excluded socket or broken symbolic link is
+	// not an error).  Symbolic links are followed to determine isDir (a broken link is not a directory).
+	Exclude func(name string, isDir bool) bool
+
+	// Labels returns the labels for the part with the given name (optional)
+	Labels func(name string) map[string]string
+
+	// Archive writes the archive of the directory dir in fsys to w (optional, defaults to archive.Tar).
+	// The size and digest of a directory part are that of its archive.
+	Archive func(fsys fs.FS, dir string, w io.Writer) error
+}
+
+// FromDirectory creates a bottle with a part for each top-level entry in fsys.
+// Regular files become file parts and directories become directory parts (with a trailing slash).
+// The size and digest of each part are computed from the content.  Parts are sorted by name.
+func FromDirectory(fsys fs.FS, opts DirectoryOptions) (*Bottle, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading bottle directory: %w", err)
+	}
+
+	content := &migrate.FSContentProvider{FS: fsys, Archive: opts.Archive}
+	parts := make([]Part, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		// follow symbolic links, but only fail on a broken link if it is not excluded
+		info, statErr := fs.Stat(fsys, name)
+		isDir := entry.IsDir()
+		if statErr == nil {
+			isDir = info.IsDir()
+		}
+		if opts.Exclude != nil && opts.Exclude(name, isDir) {
+			continue
+		}
+		if statErr != nil {
+			return nil, fmt.Errorf("part %q: %w", name, statErr)
+		}
+		switch {
+		case info.IsDir():
+		case info.Mode().IsRegular():
+		default:
+			return nil, fmt.Errorf("part %q: unsupported file type %s", name, info.Mode().Type())
+		}
+		if !util.IsPortableFilename(name) {
+			return nil, fmt.Errorf("part %q: name contains invalid (non-portable) characters", name)
+		}
+
+		size, dgst, err := content.PartContent(name)
+		if err != nil {
+			return nil, err
+		}
+		part := Part{
+			Name:   name,
+			Size:   size,
+			Digest: dgst,
+		}
+		if info.IsDir() {
+			part.Name += "/"
+		}
+		if opts.Labels != nil {
+			part.Labels = opts.Labels(name)
+		}
+		parts = append(parts, part)
+	}
+
+	// the trailing slash changes the order (e.g., "a.txt" < "a/") so we sort on the part name
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Name < parts[j].Name
+	})
+
+	if err := partIssuesWithContext(context.Background(), field.NewPath("parts"), parts).Err(); err != nil {
+		return nil, err
+	}
+
+	bottle := NewBottle()
+	bottle.Parts = parts
+	return &bottle, nil
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/act3-ai/bottle-schema/pkg/archive"
+)
+
+func TestFromDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	fsys := fstest.MapFS{
+		"a.txt":          &fstest.MapFile{Data: []byte("hello")},
+		"a/b.txt":        &fstest.MapFile{Data: []byte("b")},
+		"model.onnx":     &fstest.MapFile{Data: []byte("model")},
+		".dt/entry.yaml": &fstest.MapFile{Data: []byte("kind: Bottle")},
+		"scratch/x":      &fstest.MapFile{Data: []byte("x")},
+	}
+	fakeArchive := func(fsys fs.FS, dir string, w io.Writer) error {
+		_, err := io.WriteString(w, "archive of "+dir)
+		return err
+	}
+
+	// the default archive is reproducible
+	buf := &bytes.Buffer{}
+	assert.NoError(archive.Tar(fsys, "a", buf))
+	bottle, err := FromDirectory(fsys, DirectoryOptions{})
+	assert.NoError(err)
+	assert.Equal(Part{Name: "a/", Size: int64(buf.Len()), Digest: digest.FromBytes(buf.Bytes())}, bottle.Parts[1])
+
+	bottle, err = FromDirectory(fsys, DirectoryOptions{
+		Archive: fakeArchive,
+		Exclude: func(name string, isDir bool) bool {
+			return name == "scratch" && isDir
+		},
+		Labels: func(name string) map[string]string {
+			if name == "model.onnx" {
+				return map[string]string{"type": "model"}
+			}
+			return nil
+		},
+	})
+	assert.NoError(err)
+	assert.Equal([]Part{
+		{Name: "a.txt", Size: 5, Digest: digest.FromString("hello")},
+		{Name: "a/", Size: int64(len("archive of a")), Digest: digest.FromString("archive of a")},
+		{Name: "model.onnx", Size: 5, Digest: digest.FromString("model"), Labels: map[string]string{"type": "model"}},
+	}, bottle.Parts)
+	assert.NoError(bottle.Validate())
+
+	_, err = FromDirectory(fstest.MapFS{"bad name.txt": &fstest.MapFile{}}, DirectoryOptions{})
+	assert.ErrorContains(err, "non-portable")
+
+	_, err = FromDirectory(fstest.MapFS{"pipe": &fstest.MapFile{Mode: fs.ModeNamedPipe}}, DirectoryOptions{})
+	assert.ErrorContains(err, "unsupported file type")
+
+	// excluded entries are not rejected
+	bottle, err = FromDirectory(fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte("hello")},
+		"pipe":  &fstest.MapFile{Mode: fs.ModeNamedPipe},
+	}, DirectoryOptions{
+		Exclude: func(name string, _ bool) bool {
+			return name == "pipe"
+		},
+	})
+	assert.NoError(err)
+	assert.Equal([]Part{{Name: "a.txt", Size: 5, Digest: digest.FromString("hello")}}, bottle.Parts)
+}
+
+func TestFromDirectory_BrokenSymlink(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o644))
+	require.NoError(t, os.Symlink("missing", filepath.Join(dir, "dangling")))
+	fsys := os.DirFS(dir)
+
+	_, err := FromDirectory(fsys, DirectoryOptions{})
+	assert.ErrorContains(err, `part "dangling"`)
+
+	// an excluded broken link does not abort the walk
+	bottle, err := FromDirectory(fsys, DirectoryOptions{
+		Exclude: func(name string, isDir bool) bool {
+			return name == "dangling" && !isDir
+		},
+	})
+	assert.NoError(err)
+	assert.Equal([]Part{{Name: "a.txt", Size: 5, Digest: digest.FromString("hello")}}, bottle.Parts)
+}
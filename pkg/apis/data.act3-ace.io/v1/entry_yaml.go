@@ -0,0 +1,221 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	yamljson "sigs.k8s.io/yaml"
+
+	util "github.com/act3-ai/bottle-schema/pkg/apis/internal/yaml"
+)
+
+// EntryYAML is a bottle definition document (entry.yaml) that can be updated without losing the user's comments,
+// key ordering and formatting.  It is normally created by ToDocumentedYAML and then edited by the user.
+type EntryYAML struct {
+	doc    *yaml.Node
+	indent int
+}
+
+// ParseEntryYAML parses the YAML document in data for editing.
+// Empty data is allowed, in which case the documented YAML is used on the first call to Update.
+func ParseEntryYAML(data []byte) (*EntryYAML, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("parsing bottle YAML: %w", err)
+	}
+	if doc.Kind != 0 && (len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode) {
+		return nil, errors.New("bottle YAML must be a mapping")
+	}
+	util.PreserveBlankLines(data, doc)
+	return &EntryYAML{doc: doc, indent: util.DetectIndent(data)}, nil
+}
+
+// Bottle decodes the document into a bottle
+func (e *EntryYAML) Bottle() (*Bottle, error) {
+	b := &Bottle{}
+	if e.doc.Kind == 0 {
+		return b, nil
+	}
+	data, err := yaml.Marshal(e.doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := yamljson.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("decoding bottle YAML: %w", err)
+	}
+	return b, nil
+}
+
+// Update changes the document to hold the bottle b.
+// Fields with unchanged values are left as is, changed values are merged into the existing YAML (keeping comments
+// wherever possible), and new fields are appended with the same documentation that ToDocumentedYAML adds.
+// Parts are written after the documented fields, and the parts key is removed from the document when b has no parts.
+func (e *EntryYAML) Update(b Bottle) error {
+	tmpl, err := documentedNodes(b)
+	if err != nil {
+		return err
+	}
+	if e.doc.Kind == 0 {
+		e.doc = tmpl
+	} else if err := mergeDocumented(e.doc.Content[0], tmpl.Content[0]); err != nil {
+		return err
+	}
+	root := e.doc.Content[0]
+
+	// We do not output Parts in the documented YAML view so they are handled separately
+	if len(b.Parts) == 0 {
+		removeKey(root, "parts")
+		return nil
+	}
+	nodes, err := util.ToYamlNodes(b.Parts)
+	if err != nil {
+		return err
+	}
+	pruneEmptyFields(nodes[0])
+	if !setValue(root, "parts", nodes[0]) {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "parts"}, nodes[0])
+	}
+	return nil
+}
+
+// Marshal encodes the document using the indentation of the original document
+func (e *EntryYAML) Marshal() ([]byte, error) {
+	if e.doc.Kind == 0 {
+		return nil, nil
+	}
+	buf := &bytes.Buffer{}
+	if err := util.Encode(buf, e.doc, e.indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EditEntryYAML decodes the bottle in data, calls edit to modify it and returns the updated YAML, preserving
+// the comments and formatting of data.
+func EditEntryYAML(data []byte, edit func(b *Bottle) error) ([]byte, error) {
+	entry, err := ParseEntryYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	b, err := entry.Bottle()
+	if err != nil {
+		return nil, err
+	}
+	if err := edit(b); err != nil {
+		return nil, err
+	}
+	if err := entry.Update(*b); err != nil {
+		return nil, err
+	}
+	return entry.Marshal()
+}
+
+// mergeDocumented merges the fields of the documented YAML (newRoot) into root
+func mergeDocumented(root, newRoot *yaml.Node) error {
+	footers, err := templateFooters()
+	if err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(newRoot.Content); i += 2 {
+		key, value := newRoot.Content[i], newRoot.Content[i+1]
+		if !setValue(root, key.Value, value) {
+			if !isEmptyNode(value) {
+				root.Content = append(root.Content, key, value)
+			}
+			continue
+		}
+		// the example in the footer is only shown while the field is empty
+		if oldKey, _ := util.MappingValue(root, key.Value); !isEmptyNode(value) && oldKey.FootComment == footers[key.Value] {
+			oldKey.FootComment = ""
+		}
+	}
+	return nil
+}
+
+// documentedNodes returns the parsed documented YAML for b (without the empty fields of list items)
+func documentedNodes(b Bottle) (*yaml.Node, error) {
+	data, err := b.ToDocumentedYAML()
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	util.PreserveBlankLines(data, doc)
+	root := doc.Content[0]
+	for i := 1; i < len(root.Content); i += 2 {
+		pruneEmptyFields(root.Content[i])
+	}
+	return doc, nil
+}
+
+// pruneEmptyFields removes the fields with empty string values from the items of the sequence node n.
+// The items are structs and the YAML encoder does not omit empty fields like the JSON encoder does.
+func pruneEmptyFields(n *yaml.Node) {
+	if n.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		content := item.Content[:0]
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			v := item.Content[i+1]
+			if v.Kind == yaml.ScalarNode && v.Tag == "!!str" && v.Value == "" {
+				continue
+			}
+			content = append(content, item.Content[i], v)
+		}
+		item.Content = content
+	}
+}
+
+// templateFooters returns the (parsed) footer comments of the documented YAML for an empty bottle, keyed by field
+var templateFooters = sync.OnceValues(func() (map[string]string, error) {
+	doc, err := documentedNodes(NewBottle())
+	if err != nil {
+		return nil, err
+	}
+	footers := map[string]string{}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if f := root.Content[i].FootComment; f != "" {
+			footers[root.Content[i].Value] = f
+		}
+	}
+	return footers, nil
+})
+
+// setValue merges value into the value of key in the mapping node m.  It returns false if key is not in m.
+func setValue(m *yaml.Node, key string, value *yaml.Node) bool {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = util.MergeNode(m.Content[i+1], value)
+			return true
+		}
+	}
+	return false
+}
+
+// removeKey removes key (and its value) from the mapping node m
+func removeKey(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// isEmptyNode returns true if n is an empty scalar or collection
+func isEmptyNode(n *yaml.Node) bool {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value == ""
+	}
+	return len(n.Content) == 0
+}
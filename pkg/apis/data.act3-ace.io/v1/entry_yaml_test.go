@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditEntryYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	original := `# ACE Data Bottle definition document containing the metadata
+
+apiVersion: data.act3-ace.io/v1
+kind: Bottle
+
+# my labels
+labels:
+  mykey: myvalue # keep this one
+  a: b
+
+# A human readable description of this Bottle.
+description: |-
+  My bottle name
+  My cool bottle is so neat!
+
+# Contact information for bottle authors
+authors: []
+# - name: Your full name
+#   email: someone@example.com
+#   url: https://myhomepage.example.com # optional
+
+# Contains metric data for a given experiment
+metrics:
+  - name: AUC # area under the curve
+    value: "0.9"
+`
+
+	out, err := EditEntryYAML([]byte(original), func(b *Bottle) error {
+		assert.Equal("My bottle name\nMy cool bottle is so neat!", b.Description)
+		delete(b.Labels, "a")
+		b.Labels["new"] = "label"
+		b.Authors = append(b.Authors, Author{Name: "Jane Smith", Email: "jane.smith@example.com"})
+		b.Metrics[0].Value = "0.95"
+		b.Deprecates = append(b.Deprecates, "sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9")
+		return nil
+	})
+	assert.NoError(err)
+
+	expected := `# ACE Data Bottle definition document containing the metadata
+
+apiVersion: data.act3-ace.io/v1
+kind: Bottle
+
+# my labels
+labels:
+  mykey: myvalue # keep this one
+  new: label
+
+# A human readable description of this Bottle.
+description: |-
+  My bottle name
+  My cool bottle is so neat!
+
+# Contact information for bottle authors
+authors:
+  - name: Jane Smith
+    email: jane.smith@example.com
+
+# Contains metric data for a given experiment
+metrics:
+  - name: AUC # area under the curve
+    value: "0.95"
+
+# Bottle ID(s) to be deprecated by this bottle
+deprecates:
+  - sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9
+`
+	assert.Equal(expected, string(out))
+
+	// no changes is a round trip
+	out, err = EditEntryYAML([]byte(original), func(b *Bottle) error { return nil })
+	assert.NoError(err)
+	assert.Equal(original, string(out))
+}
+
+func TestEntryYAML_Template(t *testing.T) {
+	assert := assert.New(t)
+	bottle := testBottle()
+
+	entry, err := ParseEntryYAML(nil)
+	assert.NoError(err)
+	assert.NoError(entry.Update(*bottle))
+	out, err := entry.Marshal()
+	assert.NoError(err)
+
+	// the parts are added after the documented fields
+	assert.Contains(string(out), `# Bottle ID(s) to be deprecated by this bottle
+deprecates:
+    - sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9
+    - sha256:2dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c9
+parts:
+    - name: file.txt
+      size: 45
+`)
+
+	// parse the documented YAML back in
+	entry, err = ParseEntryYAML(out)
+	assert.NoError(err)
+	decoded, err := entry.Bottle()
+	assert.NoError(err)
+	// empty fields are decoded as empty (not nil) since the documented YAML includes them
+	assert.Equal(bottle.Labels, decoded.Labels)
+	assert.Empty(decoded.Annotations)
+	assert.Equal(bottle.Description, decoded.Description)
+	assert.Equal(bottle.Sources, decoded.Sources)
+	assert.Empty(decoded.Authors)
+	assert.Equal(bottle.PublicArtifacts, decoded.PublicArtifacts)
+	assert.Equal(bottle.Deprecates, decoded.Deprecates)
+	assert.Equal(bottle.Parts, decoded.Parts)
+
+	// removing all the parts removes the key
+	bottle.Parts = nil
+	assert.NoError(entry.Update(*bottle))
+	out, err = entry.Marshal()
+	assert.NoError(err)
+	assert.NotContains(string(out), "parts:")
+	decoded, err = entry.Bottle()
+	assert.NoError(err)
+	assert.Empty(decoded.Parts)
+	assert.Equal(bottle.Deprecates, decoded.Deprecates)
+
+	_, err = ParseEntryYAML([]byte("- not a mapping"))
+	assert.Error(err)
+}
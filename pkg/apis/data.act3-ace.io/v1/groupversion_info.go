@@ -43,6 +43,14 @@ func addKnownConversions(scheme *runtime.Scheme) error {
 		return err
 	}
 
+	if err := conversion.AddConversionFuncHelper(scheme, Convert_v1_Bottle_To_v1beta1_Bottle); err != nil {
+		return err
+	}
+
+	if err := conversion.AddConversionFuncHelper(scheme, Convert_v1_Bottle_To_v1alpha5_Bottle); err != nil {
+		return err
+	}
+
 	// if err := scheme.AddIgnoredConversionType((*Bottle)(nil), (*Bottle)(nil)); err != nil {
 	// 	return err
 	// }
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// LayerInfo describes the layer of a part
+type LayerInfo struct {
+	// MediaType of the layer (optional).
+	// Defaults to MediaTypeLayerTar for directory parts and MediaTypeLayer for file parts.
+	MediaType string
+
+	// Digest of the layer.
+	// For uncompressed layers (MediaTypeLayerTar and MediaTypeLayer) it defaults to the digest of the part.
+	Digest digest.Digest
+
+	// Size of the layer.
+	// For uncompressed layers (MediaTypeLayerTar and MediaTypeLayer) it defaults to the size of the part.
+	Size int64
+
+	// Annotations for the layer (optional)
+	Annotations map[string]string
+}
+
+// ManifestOptions are the options for BuildManifest
+type ManifestOptions struct {
+	// ArtifactType of the manifest (optional), usually mediatype.MediaTypeBottle
+	ArtifactType string
+
+	// Annotations of the manifest (optional)
+	Annotations map[string]string
+}
+
+// BuildManifest creates the manifest for the bottle with one layer per part (in the same order).
+// The config descriptor is for the canonical JSON of the bottle (see CanonicalJSON).
+// Directory parts (with a trailing slash) must have archived layers and file parts must not.
+// New bottles may not use the "Old" or "Legacy" layer media types.
+// The manifest is validated with validation.ValidateManifest and the bottle with the manifest in the context.
+func BuildManifest(bottle Bottle, layers []LayerInfo, opts ManifestOptions) (*ocispecv1.Manifest, error) {
+	if len(layers) != len(bottle.Parts) {
+		return nil, fmt.Errorf("number of parts (%d) is not equal to the number of layers (%d)", len(bottle.Parts), len(layers))
+	}
+
+	config, err := bottle.CanonicalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("encoding bottle config: %w", err)
+	}
+
+	manifest := &ocispecv1.Manifest{
+		Versioned:    ocispec.Versioned{SchemaVersion: 2},
+		MediaType:    ocispecv1.MediaTypeImageManifest,
+		ArtifactType: opts.ArtifactType,
+		Config: ocispecv1.Descriptor{
+			MediaType: mediatype.MediaTypeBottleConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		},
+		Layers:      make([]ocispecv1.Descriptor, len(layers)),
+		Annotations: opts.Annotations,
+	}
+
+	for i, l := range layers {
+		desc, err := layerDescriptor(bottle.Parts[i], l)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %w", i, err)
+		}
+		manifest.Layers[i] = desc
+	}
+
+	if err := val.ValidateManifest(*manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := bottle.ValidateWithContext(val.ContextWithManifest(context.Background(), manifest)); err != nil {
+		return nil, fmt.Errorf("invalid bottle: %w", err)
+	}
+	return manifest, nil
+}
+
+// layerDescriptor returns the layer descriptor for part
+func layerDescriptor(part Part, l LayerInfo) (ocispecv1.Descriptor, error) {
+	isDir := strings.HasSuffix(part.Name, "/")
+	desc := ocispecv1.Descriptor{
+		MediaType:   l.MediaType,
+		Digest:      l.Digest,
+		Size:        l.Size,
+		Annotations: l.Annotations,
+	}
+	if desc.MediaType == "" {
+		desc.MediaType = mediatype.MediaTypeLayer
+		if isDir {
+			desc.MediaType = mediatype.MediaTypeLayerTar
+		}
+	}
+
+	switch desc.MediaType {
+	case mediatype.MediaTypeLayerTarZstd, mediatype.MediaTypeLayerTarGzip, mediatype.MediaTypeLayerTar,
+		mediatype.MediaTypeLayerZstd, mediatype.MediaTypeLayer:
+	default:
+		return desc, fmt.Errorf("media type %q cannot be used to create a bottle", desc.MediaType)
+	}
+	if isDir != mediatype.IsArchived(desc.MediaType) {
+		if isDir {
+			return desc, fmt.Errorf("directory part %q must have an archived layer (not %q)", part.Name, desc.MediaType)
+		}
+		return desc, fmt.Errorf("file part %q must not have an archived layer (not %q)", part.Name, desc.MediaType)
+	}
+
+	// the layer of an uncompressed part is the part's content
+	if !mediatype.IsCompressed(desc.MediaType) && desc.Digest == "" {
+		desc.Digest = part.Digest
+		desc.Size = part.Size
+	}
+	if desc.Digest == "" {
+		return desc, fmt.Errorf("part %q: the digest of a compressed layer is required", part.Name)
+	}
+	return desc, nil
+}
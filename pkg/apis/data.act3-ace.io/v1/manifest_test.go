@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+)
+
+func TestBuildManifest(t *testing.T) {
+	assert := assert.New(t)
+
+	bottle := testBottle()
+	bottle.Parts = append(bottle.Parts,
+		Part{Name: "data/", Size: 2048, Digest: digest.FromString("data archive")},
+		Part{Name: "model/", Size: 4096, Digest: digest.FromString("model archive")},
+	)
+	config, err := bottle.CanonicalJSON()
+	assert.NoError(err)
+
+	manifest, err := BuildManifest(*bottle, []LayerInfo{
+		{},
+		{},
+		{MediaType: mediatype.MediaTypeLayerTarZstd, Digest: digest.FromString("compressed"), Size: 100, Annotations: map[string]string{"a": "b"}},
+	}, ManifestOptions{ArtifactType: mediatype.MediaTypeBottle, Annotations: map[string]string{"note": "x"}})
+	assert.NoError(err)
+
+	assert.Equal(ocispecv1.MediaTypeImageManifest, manifest.MediaType)
+	assert.Equal(mediatype.MediaTypeBottle, manifest.ArtifactType)
+	assert.Equal(map[string]string{"note": "x"}, manifest.Annotations)
+	assert.Equal(ocispecv1.Descriptor{
+		MediaType: mediatype.MediaTypeBottleConfig,
+		Digest:    digest.FromBytes(config),
+		Size:      int64(len(config)),
+	}, manifest.Config)
+	assert.Equal([]ocispecv1.Descriptor{
+		{MediaType: mediatype.MediaTypeLayer, Digest: bottle.Parts[0].Digest, Size: 45},
+		{MediaType: mediatype.MediaTypeLayerTar, Digest: bottle.Parts[1].Digest, Size: 2048},
+		{MediaType: mediatype.MediaTypeLayerTarZstd, Digest: digest.FromString("compressed"), Size: 100, Annotations: map[string]string{"a": "b"}},
+	}, manifest.Layers)
+
+	// errors
+	_, err = BuildManifest(*bottle, []LayerInfo{{}}, ManifestOptions{})
+	assert.ErrorContains(err, "number of parts (3) is not equal to the number of layers (1)")
+
+	_, err = BuildManifest(*bottle, []LayerInfo{{MediaType: mediatype.MediaTypeLayerTar}, {}, {}}, ManifestOptions{})
+	assert.ErrorContains(err, "must not have an archived layer")
+
+	_, err = BuildManifest(*bottle, []LayerInfo{{}, {MediaType: mediatype.MediaTypeLayerZstd, Digest: digest.FromString("x")}, {}}, ManifestOptions{})
+	assert.ErrorContains(err, "must have an archived layer")
+
+	_, err = BuildManifest(*bottle, []LayerInfo{{}, {}, {MediaType: mediatype.MediaTypeLayerTarGzip}}, ManifestOptions{})
+	assert.ErrorContains(err, "digest of a compressed layer is required")
+
+	_, err = BuildManifest(*bottle, []LayerInfo{{}, {MediaType: mediatype.MediaTypeLayerTarOld}, {}}, ManifestOptions{})
+	assert.ErrorContains(err, "cannot be used to create a bottle")
+
+	bottle.Kind = "NotABottle"
+	_, err = BuildManifest(*bottle, []LayerInfo{{}, {}, {}}, ManifestOptions{})
+	assert.ErrorContains(err, "invalid bottle")
+}
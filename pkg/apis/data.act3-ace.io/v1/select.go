@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/act3-ai/bottle-schema/pkg/selectors"
+	"github.com/act3-ai/bottle-schema/pkg/util"
+)
+
+// SelectedPart is a part that matches a selector
+type SelectedPart struct {
+	// Index of the part in the bottle, which is also the index of its layer in the manifest
+	Index int
+
+	// Part that was selected
+	Part Part
+}
+
+// SelectParts returns the parts (in order) whose labels match any of the selectors.
+// A nil selector set (selectors.Everything) selects every part and an empty one (selectors.Nothing) selects none.
+func (b Bottle) SelectParts(lss selectors.LabelSelectorSet) []SelectedPart {
+	var selected []SelectedPart
+	for i, p := range b.Parts {
+		if lss.Matches(labels.Set(p.Labels)) {
+			selected = append(selected, SelectedPart{Index: i, Part: p})
+		}
+	}
+	return selected
+}
+
+// Subset returns a partial bottle with only the parts selected by lss (and the public artifacts in those parts) and,
+// if a manifest is provided, the subset of the manifest with only the layers of those parts.
+// The subset manifest keeps the config descriptor of the full bottle (the bottle ID does not change) so it can be used
+// to fetch the config and the selected layers.
+func (b Bottle) Subset(manifest *ocispecv1.Manifest, lss selectors.LabelSelectorSet) (*Bottle, *ocispecv1.Manifest, error) {
+	if manifest != nil && len(manifest.Layers) != len(b.Parts) {
+		return nil, nil, fmt.Errorf("number of parts (%d) is not equal to the number of layers (%d)", len(b.Parts), len(manifest.Layers))
+	}
+	selected := b.SelectParts(lss)
+
+	partial := b.DeepCopy()
+	partial.Parts = make([]Part, len(selected))
+	for i, s := range selected {
+		partial.Parts[i] = *s.Part.DeepCopy()
+	}
+	partial.PublicArtifacts = slices.DeleteFunc(partial.PublicArtifacts, func(a PublicArtifact) bool {
+		return !slices.ContainsFunc(partial.Parts, func(p Part) bool {
+			return util.IsPathPrefix(a.Path, strings.TrimSuffix(p.Name, "/"))
+		})
+	})
+	if len(partial.PublicArtifacts) == 0 {
+		partial.PublicArtifacts = nil
+	}
+
+	if manifest == nil {
+		return partial, nil, nil
+	}
+	// the subset must not share any maps or slices with the manifest
+	subset := *manifest
+	subset.Annotations = maps.Clone(manifest.Annotations)
+	subset.Config = cloneDescriptor(manifest.Config)
+	subset.Layers = make([]ocispecv1.Descriptor, len(selected))
+	for i, s := range selected {
+		subset.Layers[i] = cloneDescriptor(manifest.Layers[s.Index])
+	}
+	if manifest.Subject != nil {
+		subject := cloneDescriptor(*manifest.Subject)
+		subset.Subject = &subject
+	}
+	return partial, &subset, nil
+}
+
+// cloneDescriptor returns a copy of the descriptor that does not share its annotations or URLs
+func cloneDescriptor(desc ocispecv1.Descriptor) ocispecv1.Descriptor {
+	desc.Annotations = maps.Clone(desc.Annotations)
+	desc.URLs = slices.Clone(desc.URLs)
+	desc.Data = slices.Clone(desc.Data)
+	if desc.Platform != nil {
+		platform := *desc.Platform
+		platform.OSFeatures = slices.Clone(platform.OSFeatures)
+		desc.Platform = &platform
+	}
+	return desc
+}
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/bottle-schema/pkg/selectors"
+)
+
+func TestBottle_SelectParts(t *testing.T) {
+	assert := assert.New(t)
+
+	bottle := testBottle()
+	bottle.Parts = []Part{
+		{Name: "file.txt", Size: 45, Digest: digest.FromString("file")},
+		{Name: "train/", Size: 2048, Digest: digest.FromString("train"), Labels: map[string]string{"subset": "train"}},
+		{Name: "test/", Size: 1024, Digest: digest.FromString("test"), Labels: map[string]string{"subset": "test", "size": "small"}},
+	}
+	bottle.PublicArtifacts = append(bottle.PublicArtifacts,
+		PublicArtifact{Name: "stats", Path: "train/stats.json", MediaType: "application/json", Digest: digest.FromString("stats")})
+
+	names := func(selected []SelectedPart) []string {
+		var s []string
+		for _, p := range selected {
+			s = append(s, p.Part.Name)
+		}
+		return s
+	}
+
+	assert.Equal([]string{"file.txt", "train/", "test/"}, names(bottle.SelectParts(selectors.Everything())))
+	assert.Empty(bottle.SelectParts(selectors.Nothing()))
+
+	lss, err := selectors.Parse([]string{"subset=test"})
+	assert.NoError(err)
+	selected := bottle.SelectParts(lss)
+	assert.Equal([]SelectedPart{{Index: 2, Part: bottle.Parts[2]}}, selected)
+
+	lss, err = selectors.Parse([]string{"subset=train", "size=small"})
+	assert.NoError(err)
+	assert.Equal([]string{"train/", "test/"}, names(bottle.SelectParts(lss)))
+
+	manifest, err := BuildManifest(*bottle, []LayerInfo{{}, {}, {}}, ManifestOptions{})
+	assert.NoError(err)
+
+	partial, subset, err := bottle.Subset(manifest, lss)
+	assert.NoError(err)
+	assert.Equal(bottle.Parts[1:], partial.Parts)
+	assert.Len(partial.PublicArtifacts, 1)
+	assert.Equal("train/stats.json", partial.PublicArtifacts[0].Path)
+	assert.Equal(manifest.Config, subset.Config)
+	assert.Equal(manifest.Layers[1:], subset.Layers)
+	assert.Len(manifest.Layers, 3)
+	assert.Len(bottle.Parts, 3)
+
+	// the subset does not share the manifest's annotations
+	manifest.Annotations = map[string]string{"a": "b"}
+	manifest.Layers[1].Annotations = map[string]string{"c": "d"}
+	_, subset, err = bottle.Subset(manifest, lss)
+	assert.NoError(err)
+	subset.Annotations["a"] = "changed"
+	subset.Layers[0].Annotations["c"] = "changed"
+	subset.Layers[0].Digest = digest.FromString("changed")
+	assert.Equal(map[string]string{"a": "b"}, manifest.Annotations)
+	assert.Equal(map[string]string{"c": "d"}, manifest.Layers[1].Annotations)
+	assert.NotEqual(subset.Layers[0].Digest, manifest.Layers[1].Digest)
+
+	lss, err = selectors.Parse([]string{"subset=test"})
+	assert.NoError(err)
+	partial, subset, err = bottle.Subset(nil, lss)
+	assert.NoError(err)
+	assert.Nil(subset)
+	assert.Nil(partial.PublicArtifacts)
+	assert.NoError(partial.Validate())
+
+	manifest.Layers = manifest.Layers[:1]
+	_, _, err = bottle.Subset(manifest, lss)
+	assert.ErrorContains(err, "number of parts (3) is not equal to the number of layers (1)")
+}
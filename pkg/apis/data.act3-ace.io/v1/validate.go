@@ -2,17 +2,28 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/act3-ai/bottle-schema/pkg/mediatype"
 	"github.com/act3-ai/bottle-schema/pkg/util"
 	val "github.com/act3-ai/bottle-schema/pkg/validation"
 )
 
+// configPath is the path used for issues with the config descriptor in the manifest (which is not part of the bottle)
+var configPath = field.NewPath("manifest", "config")
+
+// MaxPublicArtifactSize is the limit on the size of a public artifact (the size must be less than this).
+// Larger artifacts are not compatible with the Telemetry server.
+const MaxPublicArtifactSize = 1 << 20
+
 // Validate Part
 func (p Part) Validate() error {
 	return p.ValidateWithContext(context.Background())
@@ -65,37 +76,58 @@ func (m Metric) Validate() error {
 	)
 }
 
-// validateMetrics ensures that the metrics names are unique
-func validateMetrics(metrics []Metric) error {
+// metricIssues reports every metric whose name is not unique
+func metricIssues(fldPath *field.Path, metrics []Metric) val.IssueList {
+	var issues val.IssueList
 	// Metrics.Name is unique
-	metricNames := make(map[string]bool, len(metrics))
-	for _, m := range metrics {
+	metricNames := make(map[string]struct{}, len(metrics))
+	for i, m := range metrics {
 		if _, exists := metricNames[m.Name]; exists {
-			return fmt.Errorf("metric name '%s' is not unique", m.Name)
+			issues = append(issues, val.NewIssue(fldPath.Index(i).Child("name"), val.CodeNotUnique,
+				fmt.Sprintf("metric name '%s' is not unique", m.Name)))
+			continue
 		}
-		metricNames[m.Name] = true
+		metricNames[m.Name] = struct{}{}
 	}
-	return nil
+	return issues
 }
 
-// validatePartsWithContext ensures that the part name is unique and that no part is a prefix of any other part
-func validatePartsWithContext(ctx context.Context, parts []Part) error {
+// validateMetrics ensures that the metrics names are unique
+func validateMetrics(metrics []Metric) error {
+	return metricIssues(field.NewPath("metrics"), metrics).Err()
+}
+
+// partIssuesWithContext reports every part whose name is not unique, is a prefix of another part, or
+// does not agree with the layer in the "manifest" (if provided in the context)
+func partIssuesWithContext(ctx context.Context, fldPath *field.Path, parts []Part) val.IssueList {
+	var issues val.IssueList
 	if manifest := val.ManifestFromContext(ctx); manifest != nil {
 		nLayers := len(manifest.Layers)
 		nParts := len(parts)
 		if nLayers != nParts {
-			return fmt.Errorf("number of parts (%d) is not equal to the number of layers (%d)", nParts, nLayers)
-		}
-
-		for i, p := range parts {
-			layer := manifest.Layers[i]
-			if mediatype.IsArchived(layer.MediaType) {
-				if !strings.HasSuffix(p.Name, "/") {
-					return fmt.Errorf("part '%s' (index %d) is an archive thus it must have a trailing slash", p.Name, i)
+			issues = append(issues, val.NewIssue(fldPath, val.CodeLayerCount,
+				fmt.Sprintf("number of parts (%d) is not equal to the number of layers (%d)", nParts, nLayers)))
+		} else {
+			for i, p := range parts {
+				layer := manifest.Layers[i]
+				if layer.MediaType == "" {
+					issues = append(issues, val.NewIssue(fldPath.Index(i), val.CodeInvalid,
+						fmt.Sprintf("layer %d of the manifest has no media type", i)))
+					continue
+				}
+				if mediatype.IsArchived(layer.MediaType) {
+					if !strings.HasSuffix(p.Name, "/") {
+						issues = append(issues, val.NewIssue(fldPath.Index(i).Child("name"), val.CodeTrailingSlash,
+							fmt.Sprintf("part '%s' (index %d) is an archive thus it must have a trailing slash", p.Name, i)))
+					}
+				} else {
+					if strings.HasSuffix(p.Name, "/") {
+						issues = append(issues, val.NewIssue(fldPath.Index(i).Child("name"), val.CodeNoTrailingSlash,
+							fmt.Sprintf("part '%s' (index %d) is not an archive thus it must not have a trailing slash", p.Name, i)))
+					}
 				}
-			} else {
-				if strings.HasSuffix(p.Name, "/") {
-					return fmt.Errorf("part '%s' (index %d) is not an archive thus it must not have a trailing slash", p.Name, i)
+				if val.LayerConsistencyFromContext(ctx) {
+					issues = append(issues, layerIssues(fldPath.Index(i), i, p, layer)...)
 				}
 			}
 		}
@@ -104,8 +136,11 @@ func validatePartsWithContext(ctx context.Context, parts []Part) error {
 	// Parts.Name is unique
 	partNames := make(map[string]struct{}, len(parts))
 	for i, p := range parts {
+		namePath := fldPath.Index(i).Child("name")
 		if _, exists := partNames[p.Name]; exists {
-			return fmt.Errorf("part name '%s' is not unique", p.Name)
+			issues = append(issues, val.NewIssue(namePath, val.CodeNotUnique,
+				fmt.Sprintf("part name '%s' is not unique", p.Name)))
+			continue
 		}
 		partNames[p.Name] = struct{}{}
 
@@ -115,45 +150,154 @@ func validatePartsWithContext(ctx context.Context, parts []Part) error {
 		// foo/bar
 		// foo/dog
 		for j, otherPart := range parts {
-			if i == j {
+			if i == j || otherPart.Name == p.Name {
 				continue
 			}
 			if util.IsPathPrefix(otherPart.Name, p.Name) {
-				return fmt.Errorf("part '%s' is invalid because it is a prefix of part '%s'", p.Name, otherPart.Name)
+				issues = append(issues, val.NewIssue(namePath, val.CodePartPrefix,
+					fmt.Sprintf("part '%s' is invalid because it is a prefix of part '%s'", p.Name, otherPart.Name)))
 			}
 		}
 	}
-	return nil
+	return issues
+}
+
+// maxCompressedSize is the largest plausible size of the compressed layer for a part of the given size.
+// Compression can expand incompressible data but only by a small amount (plus the headers).
+func maxCompressedSize(size int64) int64 {
+	return size + size/64 + 1024
+}
+
+// layerIssues reports the inconsistencies between the part (at index i) and its layer.
+// Uncompressed layers contain exactly the part's content so they must have the same digest and size.
+// Compressed layers can only be checked for plausibility.
+func layerIssues(fldPath *field.Path, i int, p Part, layer ocispecv1.Descriptor) val.IssueList {
+	var issues val.IssueList
+	switch layer.MediaType {
+	case mediatype.MediaTypeLayer, mediatype.MediaTypeLayerRawOld, mediatype.MediaTypeLayerRawLegacy, mediatype.MediaTypeLayerTar:
+		if layer.Digest != p.Digest {
+			issues = append(issues, val.NewIssue(fldPath.Child("digest"), val.CodeLayerDigest,
+				fmt.Sprintf("part '%s' (index %d) has digest %s but its uncompressed layer has digest %s", p.Name, i, p.Digest, layer.Digest)))
+		}
+		if layer.Size != p.Size {
+			issues = append(issues, val.NewIssue(fldPath.Child("size"), val.CodeLayerSize,
+				fmt.Sprintf("part '%s' (index %d) has size %d but its uncompressed layer has size %d", p.Name, i, p.Size, layer.Size)))
+		}
+	default:
+		if !mediatype.IsCompressed(layer.MediaType) {
+			// the old archive formats include the part name so the layer is not the part's content
+			break
+		}
+		if layer.Size == 0 {
+			issues = append(issues, val.NewIssue(fldPath.Child("size"), val.CodeLayerImplausible,
+				fmt.Sprintf("part '%s' (index %d) has an empty compressed layer", p.Name, i)))
+		} else if layer.Size > maxCompressedSize(p.Size) {
+			issues = append(issues, val.NewWarning(fldPath.Child("size"), val.CodeLayerImplausible,
+				fmt.Sprintf("part '%s' (index %d) has size %d but its compressed layer is larger (%d)", p.Name, i, p.Size, layer.Size)))
+		}
+		if p.Digest != "" && layer.Digest == p.Digest {
+			issues = append(issues, val.NewWarning(fldPath.Child("digest"), val.CodeLayerImplausible,
+				fmt.Sprintf("part '%s' (index %d) has the same digest as its compressed layer (the layer is probably not compressed)", p.Name, i)))
+		}
+	}
+	return issues
+}
+
+// configIssues reports the differences between the config descriptor in the "manifest" (if provided in the context)
+// and the canonical JSON of the bottle.  These are only checked if the layer consistency checks are enabled.
+func configIssues(ctx context.Context, fldPath *field.Path, b Bottle) val.IssueList {
+	manifest := val.ManifestFromContext(ctx)
+	if manifest == nil || !val.LayerConsistencyFromContext(ctx) {
+		return nil
+	}
+	data, err := b.CanonicalJSON()
+	if err != nil {
+		return val.IssueList{val.NewIssue(fldPath, val.CodeInternal, err.Error())}
+	}
+
+	var issues val.IssueList
+	config := manifest.Config
+	if alg := config.Digest.Algorithm(); !alg.Available() || config.Digest != alg.FromBytes(data) {
+		issues = append(issues, val.NewIssue(fldPath.Child("digest"), val.CodeConfigDigest,
+			fmt.Sprintf("config digest %s is not the digest of the canonical bottle JSON", config.Digest)))
+	}
+	if config.Size != int64(len(data)) {
+		issues = append(issues, val.NewIssue(fldPath.Child("size"), val.CodeConfigSize,
+			fmt.Sprintf("config size %d is not the size of the canonical bottle JSON (%d)", config.Size, len(data))))
+	}
+	return issues
 }
 
-// TODO might also want to check that the number of layers equals the number of parts
+// validatePartsWithContext ensures that the part name is unique and that no part is a prefix of any other part
+func validatePartsWithContext(ctx context.Context, parts []Part) error {
+	return partIssuesWithContext(ctx, field.NewPath("parts"), parts).Err()
+}
 
-// validatePublicArtifacts validates public artifacts path is unique and that each artifact belongs to a single part
-func validatePublicArtifacts(b Bottle) error {
+// publicArtifactIssues reports every public artifact whose path is not unique or does not belong to a single part
+func publicArtifactIssues(fldPath *field.Path, b Bottle) val.IssueList {
+	var issues val.IssueList
 	// PublicArtifacts.Path is unique
-	artifactPaths := make(map[string]struct{}, len(b.Parts))
-	for _, a := range b.PublicArtifacts {
+	artifactPaths := make(map[string]struct{}, len(b.PublicArtifacts))
+	for i, a := range b.PublicArtifacts {
+		pathPath := fldPath.Index(i).Child("path")
 		if _, exists := artifactPaths[a.Path]; exists {
-			return fmt.Errorf("public artifact path '%s' is not unique", a.Path)
+			issues = append(issues, val.NewIssue(pathPath, val.CodeNotUnique,
+				fmt.Sprintf("public artifact path '%s' is not unique", a.Path)))
+			continue
 		}
 		artifactPaths[a.Path] = struct{}{}
 
 		// artifact must be in exactly one part
 		enclosingParts := 0
 		for _, p := range b.Parts {
-			if util.IsPathPrefix(a.Path, p.Name) {
+			if util.IsPathPrefix(a.Path, strings.TrimSuffix(p.Name, "/")) {
 				enclosingParts++
 			}
 		}
 		if enclosingParts == 0 {
-			return fmt.Errorf("public artifact path '%s' is not in any part", a.Path)
+			issues = append(issues, val.NewIssue(pathPath, val.CodeArtifactNotInPart,
+				fmt.Sprintf("public artifact path '%s' is not in any part", a.Path)))
 		}
 		if enclosingParts > 1 {
 			// This might not be possible given the requirements on parts.Name
-			return fmt.Errorf("public artifact path '%s' is in more multiple parts (the parts are specified incorrectly)", a.Path)
+			issues = append(issues, val.NewIssue(pathPath, val.CodeArtifactInMultipleParts,
+				fmt.Sprintf("public artifact path '%s' is in more multiple parts (the parts are specified incorrectly)", a.Path)))
+		}
+	}
+	return issues
+}
+
+// publicArtifactSizeIssues reports every public artifact that is not smaller than MaxPublicArtifactSize.
+// The sizes are only checked if an ArtifactSizeFunc is provided in the context.
+func publicArtifactSizeIssues(ctx context.Context, fldPath *field.Path, b Bottle) val.IssueList {
+	size := val.ArtifactSizeFromContext(ctx)
+	if size == nil {
+		return nil
+	}
+	var issues val.IssueList
+	for i, a := range b.PublicArtifacts {
+		n, err := size(a.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// not available so it cannot be checked
+		case err != nil:
+			issues = append(issues, val.NewIssue(fldPath.Index(i).Child("path"), val.CodeArtifactMissing,
+				fmt.Sprintf("public artifact '%s': %v", a.Path, err)))
+		case n >= MaxPublicArtifactSize:
+			issues = append(issues, val.NewIssue(fldPath.Index(i).Child("path"), val.CodeArtifactTooLarge,
+				fmt.Sprintf("public artifact '%s' has size %d but it must be smaller than %d bytes", a.Path, n, MaxPublicArtifactSize)))
 		}
 	}
-	return nil
+	return issues
+}
+
+// validatePublicArtifactsWithContext validates public artifacts path is unique and that each artifact belongs to a
+// single part.  Sizes are checked if an ArtifactSizeFunc is provided in the context.
+func validatePublicArtifactsWithContext(ctx context.Context, b Bottle) error {
+	fldPath := field.NewPath("publicArtifacts")
+	issues := publicArtifactIssues(fldPath, b)
+	issues = append(issues, publicArtifactSizeIssues(ctx, fldPath, b)...)
+	return issues.Err()
 }
 
 // Validate Bottle using ozzo-validation
@@ -163,8 +307,9 @@ func (b Bottle) Validate() error {
 
 // ValidateWithContext Bottle using ozzo-validation
 // If a "manifest" is provided in the context that is used for further validation
+// If an ArtifactSizeFunc is provided in the context the public artifact sizes are checked
 func (b Bottle) ValidateWithContext(ctx context.Context) error {
-	return validation.ValidateStructWithContext(ctx, &b,
+	err := validation.ValidateStructWithContext(ctx, &b,
 		validation.Field(&b.APIVersion, validation.Required, validation.In(GroupVersion.String())),
 		validation.Field(&b.Kind, validation.Required, validation.In("Bottle")),
 		validation.Field(&b.Labels, val.KubernetesLabels),
@@ -174,11 +319,62 @@ func (b Bottle) ValidateWithContext(ctx context.Context) error {
 		validation.Field(&b.Metrics, validation.By(func(value any) error {
 			return validateMetrics(value.([]Metric))
 		})),
-		validation.Field(&b.PublicArtifacts, validation.By(func(value any) error {
-			return validatePublicArtifacts(b)
+		validation.Field(&b.PublicArtifacts, validation.WithContext(func(ctx context.Context, value any) error {
+			return validatePublicArtifactsWithContext(ctx, b)
 		})),
 		validation.Field(&b.Parts, validation.WithContext(func(ctx context.Context, value any) error {
 			return validatePartsWithContext(ctx, value.([]Part))
 		})),
 	)
+
+	// the config is not a field of the bottle so it is added separately
+	configErr := configIssues(ctx, configPath, b).Err()
+	if configErr == nil {
+		return err
+	}
+	errs := validation.Errors{}
+	if err != nil && !errors.As(err, &errs) {
+		return err
+	}
+	errs[configPath.String()] = configErr
+	return errs
+}
+
+// ValidationReport validates the bottle and returns a report listing every problem found, each with the path to the
+// offending field.  If a "manifest" is provided in the context that is used for further validation.
+// If an ArtifactSizeFunc is provided in the context the public artifact sizes are checked.
+// Use Report.Err() to determine if the bottle is valid.
+func (b Bottle) ValidationReport(ctx context.Context) *val.Report {
+	report := &val.Report{}
+	report.AddError(field.NewPath("apiVersion"), validation.Validate(b.APIVersion, validation.Required, validation.In(GroupVersion.String())))
+	report.AddError(field.NewPath("kind"), validation.Validate(b.Kind, validation.Required, validation.In("Bottle")))
+	report.Add(val.LabelIssues(field.NewPath("labels"), b.Labels)...)
+	report.Add(val.AnnotationIssues(field.NewPath("annotations"), b.Annotations)...)
+
+	for i, s := range b.Sources {
+		report.AddError(field.NewPath("sources").Index(i), s.Validate())
+	}
+
+	for i, a := range b.Authors {
+		report.AddError(field.NewPath("authors").Index(i), a.Validate())
+	}
+
+	for i, m := range b.Metrics {
+		report.AddError(field.NewPath("metrics").Index(i), m.Validate())
+	}
+	report.Add(metricIssues(field.NewPath("metrics"), b.Metrics)...)
+
+	for i, a := range b.PublicArtifacts {
+		report.AddError(field.NewPath("publicArtifacts").Index(i), a.Validate())
+	}
+	report.Add(publicArtifactIssues(field.NewPath("publicArtifacts"), b)...)
+	report.Add(publicArtifactSizeIssues(ctx, field.NewPath("publicArtifacts"), b)...)
+
+	for i, p := range b.Parts {
+		report.AddError(field.NewPath("parts").Index(i), p.ValidateWithContext(ctx))
+	}
+	report.Add(partIssuesWithContext(ctx, field.NewPath("parts"), b.Parts)...)
+	report.Add(configIssues(ctx, configPath, b)...)
+
+	return report
 }
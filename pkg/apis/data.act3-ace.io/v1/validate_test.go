@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"testing/fstest"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -150,3 +151,161 @@ func TestBottle_ValidateWithContext(t *testing.T) {
 		})
 	}
 }
+
+func TestBottle_ValidationReport(t *testing.T) {
+	assert := assert.New(t)
+
+	dgst1 := digest.Digest("sha256:9dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c0")
+	dgst2 := digest.Digest("sha256:8dab955c282ecaacf81b1e1eda09300d42dfebf148583eef2b38ddd342da77c0")
+	manifest := &ocispec.Manifest{
+		Layers: []ocispec.Descriptor{
+			{MediaType: mediatype.MediaTypeLayerTarGzip},
+			{MediaType: mediatype.MediaTypeLayer},
+			{MediaType: mediatype.MediaTypeLayer},
+		},
+	}
+	ctxManifest := val.ContextWithManifest(context.Background(), manifest)
+
+	bottle := testBottle()
+	bottle.Labels["key with space"] = "ok"
+	bottle.Metrics = []Metric{
+		{Name: "loss", Value: "0.5"},
+		{Name: "loss", Value: "dog"},
+	}
+	bottle.PublicArtifacts = append(bottle.PublicArtifacts, PublicArtifact{
+		Name:      "missing",
+		Path:      "missing.txt",
+		MediaType: "text/plain",
+		Digest:    dgst1,
+	})
+	bottle.Parts = []Part{
+		{"dogs", 13, dgst1, nil},
+		{"cats/", 20, dgst2, nil},
+		{"file.txt", 45, "sha256:deedbeef", nil},
+	}
+
+	report := bottle.ValidationReport(ctxManifest)
+	assert.Error(report.Err())
+
+	type found struct {
+		Field string
+		Code  string
+	}
+	got := make([]found, len(report.Issues))
+	for i, issue := range report.Issues {
+		got[i] = found{issue.Field, issue.Code}
+	}
+	assert.Equal([]found{
+		{"labels[key with space]", val.CodeInvalidLabel},
+		{"metrics[1].value", "validation_is_float"},
+		{"metrics[1].name", val.CodeNotUnique},
+		{"publicArtifacts[1].path", val.CodeArtifactNotInPart},
+		{"parts[2].digest", val.CodeInvalid},
+		{"parts[0].name", val.CodeTrailingSlash},
+		{"parts[1].name", val.CodeNoTrailingSlash},
+	}, got)
+
+	// the same bottle also fails the regular validation
+	assert.Error(bottle.ValidateWithContext(ctxManifest))
+
+	// a valid bottle has no issues
+	assert.Empty(testBottle().ValidationReport(context.Background()).Issues)
+}
+
+func TestBottle_ValidationReport_LayerConsistency(t *testing.T) {
+	assert := assert.New(t)
+
+	bottle := testBottle()
+	bottle.Parts = []Part{
+		{Name: "file.txt", Size: 45, Digest: digest.FromString("file")},
+		{Name: "data/", Size: 2048, Digest: digest.FromString("data")},
+		{Name: "model/", Size: 100, Digest: digest.FromString("model")},
+		{Name: "empty/", Size: 100, Digest: digest.FromString("empty")},
+		{Name: "big.bin", Size: 100, Digest: digest.FromString("big")},
+		{Name: "ok.bin", Size: 100, Digest: digest.FromString("ok")},
+	}
+	manifest, err := BuildManifest(*bottle, []LayerInfo{
+		{},
+		{},
+		{MediaType: mediatype.MediaTypeLayerTarZstd, Digest: digest.FromString("model"), Size: 50},
+		{MediaType: mediatype.MediaTypeLayerTarGzip, Digest: digest.FromString("empty.tar.gz"), Size: 0},
+		{MediaType: mediatype.MediaTypeLayerZstd, Digest: digest.FromString("big.zst"), Size: 5000},
+		{MediaType: mediatype.MediaTypeLayerZstd, Digest: digest.FromString("ok.zst"), Size: 80},
+	}, ManifestOptions{})
+	assert.NoError(err)
+
+	// without the layer consistency checks the manifest only needs to agree with the trailing slashes
+	ctx := val.ContextWithManifest(context.Background(), manifest)
+	assert.Empty(bottle.ValidationReport(ctx).Issues)
+
+	ctx = val.ContextWithLayerConsistency(ctx)
+	type found struct {
+		Field    string
+		Code     string
+		Severity val.Severity
+	}
+	issues := func() []found {
+		report := bottle.ValidationReport(ctx)
+		got := make([]found, len(report.Issues))
+		for i, issue := range report.Issues {
+			got[i] = found{issue.Field, issue.Code, issue.Severity}
+		}
+		return got
+	}
+	assert.Equal([]found{
+		{"parts[2].digest", val.CodeLayerImplausible, val.SeverityWarning},
+		{"parts[3].size", val.CodeLayerImplausible, val.SeverityError},
+		{"parts[4].size", val.CodeLayerImplausible, val.SeverityWarning},
+	}, issues())
+
+	// uncompressed layers must match the part and the config must match the bottle (the size is unchanged)
+	bottle.Parts[0].Size = 46
+	bottle.Parts[1].Digest = digest.FromString("other")
+	assert.Equal([]found{
+		{"parts[0].size", val.CodeLayerSize, val.SeverityError},
+		{"parts[1].digest", val.CodeLayerDigest, val.SeverityError},
+		{"parts[2].digest", val.CodeLayerImplausible, val.SeverityWarning},
+		{"parts[3].size", val.CodeLayerImplausible, val.SeverityError},
+		{"parts[4].size", val.CodeLayerImplausible, val.SeverityWarning},
+		{"manifest.config.digest", val.CodeConfigDigest, val.SeverityError},
+	}, issues())
+
+	err = bottle.ValidateWithContext(ctx)
+	assert.ErrorContains(err, "manifest.config: config digest")
+	assert.ErrorContains(err, "parts: part 'file.txt' (index 0) has size 46 but its uncompressed layer has size 45")
+}
+
+func TestBottle_ValidationReport_ArtifactSize(t *testing.T) {
+	assert := assert.New(t)
+
+	bottle := testBottle()
+	bottle.Parts = append(bottle.Parts, Part{Name: "data/", Size: 2048, Digest: digest.FromString("data")})
+	bottle.PublicArtifacts = append(bottle.PublicArtifacts,
+		PublicArtifact{Name: "big", Path: "data/big.bin", MediaType: "application/octet-stream", Digest: digest.FromString("big")},
+		PublicArtifact{Name: "dir", Path: "data/sub", MediaType: "application/octet-stream", Digest: digest.FromString("sub")},
+		PublicArtifact{Name: "remote", Path: "data/remote.txt", MediaType: "text/plain", Digest: digest.FromString("remote")},
+	)
+
+	fsys := fstest.MapFS{
+		"file.txt":       {Data: []byte("file")},
+		"data/big.bin":   {Data: make([]byte, MaxPublicArtifactSize)},
+		"data/sub/a.txt": {Data: []byte("a")},
+	}
+	ctx := val.ContextWithArtifactSize(context.Background(), val.ArtifactSizeFromFS(fsys))
+
+	// sizes are only checked when requested
+	assert.Empty(bottle.ValidationReport(context.Background()).Issues)
+
+	report := bottle.ValidationReport(ctx)
+	if assert.Len(report.Issues, 2) {
+		assert.Equal("publicArtifacts[1].path", report.Issues[0].Field)
+		assert.Equal(val.CodeArtifactTooLarge, report.Issues[0].Code)
+		assert.Equal("publicArtifacts[2].path", report.Issues[1].Field)
+		assert.Equal(val.CodeArtifactMissing, report.Issues[1].Code)
+	}
+
+	assert.ErrorContains(bottle.ValidateWithContext(ctx), "must be smaller than 1048576 bytes")
+
+	fsys["data/big.bin"].Data = fsys["data/big.bin"].Data[:MaxPublicArtifactSize-1]
+	assert.Len(bottle.ValidationReport(ctx).Issues, 1)
+}
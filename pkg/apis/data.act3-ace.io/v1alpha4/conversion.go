@@ -1,17 +1,25 @@
 package v1alpha4
 
 import (
-	"time"
+	"errors"
+	"fmt"
+	"io/fs"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"github.com/opencontainers/go-digest"
 	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha2"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha3"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
 )
 
 // Convert_v1alpha2_Bottle_To_v1alpha4_Bottle is a bottle converter
 func Convert_v1alpha2_Bottle_To_v1alpha4_Bottle(in *v1alpha2.Bottle, out *Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	_, report := migrate.FromScope(scope)
+	content := migrate.ContentFromScope(scope)
+	from, to := v1alpha2.GroupVersion.String(), GroupVersion.String()
+
 	// Migrate Files -> Parts
 	// New Fields: usage, Expiration
 
@@ -34,6 +42,7 @@ func Convert_v1alpha2_Bottle_To_v1alpha4_Bottle(in *v1alpha2.Bottle, out *Bottle
 	}
 	// Migrate size -> layersize. digest -> layerdigest.
 	// Add fields: size, digest  (uncompressed data, will need recalculating)
+	partsPath := field.NewPath("parts")
 	out.Parts = make([]Part, len(in.Files))
 	for i, f := range in.Files {
 		part := Part{
@@ -43,29 +52,35 @@ func Convert_v1alpha2_Bottle_To_v1alpha4_Bottle(in *v1alpha2.Bottle, out *Bottle
 			Format:      f.Format,
 			Digest:      DigestMap{},
 			LayerDigest: DigestMap{Sha256: f.Digest.Sha256},
-			Modified:    metav1.Time{Time: time.Now()},
+			Modified:    f.Modified,
 			Labels:      f.Labels,
 		}
 		if part.Format == "" {
 			part.Format = "raw"
+			report.Defaulted(partsPath.Index(i).Child("format"), from, to, `format defaulted to "raw"`)
+		}
+
+		// the uncompressed size and digest were not included in v1alpha2 so recalculate them from the content if we can
+		recalculated, err := recalcPart(content, &part)
+		if err != nil {
+			return err
+		}
+		if !recalculated {
+			report.Recompute(partsPath.Index(i).Child("size"), from, to, "v1alpha2 only records the layer size, the uncompressed size must be recalculated")
+			report.Recompute(partsPath.Index(i).Child("digest"), from, to, "v1alpha2 only records the layer digest, the content digest must be recalculated")
 		}
 		out.Parts[i] = part
 	}
 
-	// If the controller object supports post migrate operations, this migration will use
-	//  RecalcUncompressedSizes() error   to inform the controller that uncompressed digest and size information needs to
-	//  be recalculated
-
-	// Register uncompressed size/digest calculator post migrate operation.  This data was not included in the v1alpha2
-	// if pmc, ok := m.Controller.(types.PostMigrateConsumer); ok {
-	// 	pmc.RegisterPostMigrateOp("recalc-uncompressed", recalcOp, false)
-	// }
-
 	return nil
 }
 
 // Convert_v1alpha3_Bottle_To_v1alpha4_Bottle is a bottle converter
 func Convert_v1alpha3_Bottle_To_v1alpha4_Bottle(in *v1alpha3.Bottle, out *Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	_, report := migrate.FromScope(scope)
+	content := migrate.ContentFromScope(scope)
+	from, to := v1alpha3.GroupVersion.String(), GroupVersion.String()
+
 	out.APIVersion = GroupVersion.String()
 	out.Kind = "Bottle"
 
@@ -90,6 +105,7 @@ func Convert_v1alpha3_Bottle_To_v1alpha4_Bottle(in *v1alpha3.Bottle, out *Bottle
 
 	// Migrate: size -> layersize. digest -> layerdigest. usize -> size
 	// Add fields: digest  (uncompressed data, will need recalculating)
+	partsPath := field.NewPath("parts")
 	out.Parts = make([]Part, len(in.Files))
 	for i, f := range in.Files {
 		part := Part{
@@ -99,40 +115,46 @@ func Convert_v1alpha3_Bottle_To_v1alpha4_Bottle(in *v1alpha3.Bottle, out *Bottle
 			Format:      f.Format,
 			Digest:      DigestMap{},
 			LayerDigest: DigestMap{Sha256: f.Digest.Sha256},
-			Modified:    metav1.Time{Time: time.Now()},
+			Modified:    f.Modified,
 			Labels:      f.Labels,
 		}
 		if part.Format == "" {
 			part.Format = "raw" // oci.FormatFromMediaType(oci.RawMediaType)
+			report.Defaulted(partsPath.Index(i).Child("format"), from, to, `format defaulted to "raw"`)
+		}
+
+		// the uncompressed digest was not included in v1alpha3 so recalculate it from the content if we can
+		recalculated, err := recalcPart(content, &part)
+		if err != nil {
+			return err
+		}
+		if !recalculated {
+			report.Recompute(partsPath.Index(i).Child("digest"), from, to, "v1alpha3 only records the layer digest, the content digest must be recalculated")
 		}
 		out.Parts[i] = part
 	}
 
-	// If the controller object supports post migrate operations, this migration will use
-	//  RecalcUncompressedSizes() error   to inform the controller that uncompressed digest and size information needs to
-	//  be recalculated
-
-	// Register uncompressed size/digest calculator post migrate operation.  This data was not included in the v1alpha2
-	// if pmc, ok := m.Controller.(types.PostMigrateConsumer); ok {
-	// 	pmc.RegisterPostMigrateOp("recalc-uncompressed", recalcOp, false)
-	// }
-
 	return nil
 }
 
-// recalcOp is a post migration operation that triggers a "RecalcUncompressedSizes()" function on the controller if it
-// exists.
-// func recalcOp(set any) error {
-// 	type recalculator interface {
-// 		RecalcUncompressedSizes() error
-// 	}
-// 	if r, ok := set.(recalculator); ok {
-// 		err := r.RecalcUncompressedSizes()
-// 		if os.IsNotExist(err) {
-// 			// discard post migration errors where files aren't found, which occurs if a part selector is used
-// 			return nil
-// 		}
-// 		return err
-// 	}
-// 	return nil
-// }
+// recalcPart sets the uncompressed size and digest of the part from the content provider.
+// It returns false if there is no provider or the part's content is not available (which occurs if a part selector was
+// used), in which case the values still need to be recalculated.
+func recalcPart(content migrate.PartContentProvider, part *Part) (bool, error) {
+	if content == nil {
+		return false, nil
+	}
+	size, dgst, err := content.PartContent(part.Name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("recalculating part %q: %w", part.Name, err)
+	}
+	if dgst.Algorithm() != digest.SHA256 {
+		return false, fmt.Errorf("recalculating part %q: unsupported digest algorithm %q", part.Name, dgst.Algorithm())
+	}
+	part.Size = size
+	part.Digest = DigestMap{Sha256: dgst.Encoded()}
+	return true, nil
+}
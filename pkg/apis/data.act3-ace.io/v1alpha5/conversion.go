@@ -1,20 +1,37 @@
 package v1alpha5
 
 import (
+	"fmt"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha2"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha3"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha4"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
 )
 
 // Convert_v1alpha4_Bottle_To_v1alpha5_Bottle converts Bottle from v1alpha4 to v1alpha5
 func Convert_v1alpha4_Bottle_To_v1alpha5_Bottle(in *v1alpha4.Bottle, out *Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	_, report := migrate.FromScope(scope)
+	from, to := v1alpha4.GroupVersion.String(), GroupVersion.String()
+
 	out.APIVersion = GroupVersion.String()
 	out.Kind = "Bottle"
 
+	// Removed fields: Catalog, Keywords, Expiration
+	if in.Catalog {
+		report.Dropped(field.NewPath("catalog"), from, to, "v1alpha5 does not have a catalog flag")
+	}
+	if len(in.Keywords) > 0 {
+		report.Dropped(field.NewPath("keywords"), from, to, fmt.Sprintf("v1alpha5 does not have keywords (%s)", strings.Join(in.Keywords, ", ")))
+	}
+	if in.Expiration != "" {
+		report.Dropped(field.NewPath("expiration"), from, to, fmt.Sprintf("v1alpha5 does not have an expiration (%s)", in.Expiration))
+	}
+
 	// Migrate: Maintainers -> Authors, Usage -> PublicArtifacts
 	// New Fields: Annotations, Labels, Metrics
 	out.Annotations = map[string]string{}
@@ -39,6 +56,7 @@ func Convert_v1alpha4_Bottle_To_v1alpha5_Bottle(in *v1alpha4.Bottle, out *Bottle
 		out.PublicArtifacts[i].Name = u.Name
 		out.PublicArtifacts[i].Path = u.File
 		out.PublicArtifacts[i].Type = u.Topic
+		report.Recompute(field.NewPath("publicArtifacts").Index(i).Child("digest"), from, to, "usage files do not record a digest, it must be calculated")
 	}
 
 	// If the controller object supports post migrate operations, this migration will use
@@ -83,6 +101,7 @@ func Convert_v1alpha4_Bottle_To_v1alpha5_Bottle(in *v1alpha4.Bottle, out *Bottle
 
 		if part.Format == "" {
 			part.Format = "raw"
+			report.Defaulted(field.NewPath("parts").Index(i).Child("format"), from, to, `format defaulted to "raw"`)
 		}
 		out.Parts[i] = part
 	}
@@ -109,11 +128,12 @@ func Convert_v1alpha3_Bottle_To_v1alpha5_Bottle(in *v1alpha3.Bottle, out *Bottle
 	return scope.Convert(a4, out)
 }
 
-// Convert_v1alpha2_Bottle_To_v1alpha5_Bottle converts Bottle from v1alpha3 to v1alpha5
+// Convert_v1alpha2_Bottle_To_v1alpha5_Bottle converts Bottle from v1alpha2 to v1alpha5
 func Convert_v1alpha2_Bottle_To_v1alpha5_Bottle(in *v1alpha2.Bottle, out *Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
-	a3 := &v1alpha3.Bottle{}
-	if err := scope.Convert(in, a3); err != nil {
+	// there is no v1alpha2 to v1alpha3 conversion (see the v1alpha4 migration notes)
+	a4 := &v1alpha4.Bottle{}
+	if err := scope.Convert(in, a4); err != nil {
 		return err
 	}
-	return scope.Convert(a3, out)
+	return scope.Convert(a4, out)
 }
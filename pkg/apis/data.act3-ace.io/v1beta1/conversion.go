@@ -1,18 +1,25 @@
 package v1beta1
 
 import (
+	"fmt"
+
 	"github.com/opencontainers/go-digest"
 	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha2"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha3"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha4"
 	"github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1alpha5"
 	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
 )
 
 // Convert_v1alpha5_Bottle_To_v1beta1_Bottle converts Bottle from v1alpha5 to v1beta1
 func Convert_v1alpha5_Bottle_To_v1beta1_Bottle(in *v1alpha5.Bottle, out *Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	_, report := migrate.FromScope(scope)
+	from, to := v1alpha5.GroupVersion.String(), GroupVersion.String()
+
 	out.APIVersion = GroupVersion.String()
 	out.Kind = "Bottle"
 
@@ -52,6 +59,8 @@ func Convert_v1alpha5_Bottle_To_v1beta1_Bottle(in *v1alpha5.Bottle, out *Bottle,
 			Path:      artV5.Path,
 			Digest:    digest.Digest(artV5.Digest),
 		}
+		report.Defaulted(field.NewPath("publicArtifacts").Index(i).Child("mediaType"), from, to,
+			fmt.Sprintf("media type %q determined from the path", mediaType))
 	}
 
 	// migrate parts, new part structure with layer info removed from bottle definition core schema
@@ -114,3 +123,71 @@ func Convert_v1alpha2_Bottle_To_v1beta1_Bottle(in *v1alpha2.Bottle, out *Bottle,
 	}
 	return scope.Convert(a4, out)
 }
+
+// Convert_v1beta1_Bottle_To_v1alpha5_Bottle converts Bottle from v1beta1 down to v1alpha5
+func Convert_v1beta1_Bottle_To_v1alpha5_Bottle(in *Bottle, out *v1alpha5.Bottle, scope conversion.Scope) error { //revive:disable-line:var-naming
+	manifest, report := migrate.FromScope(scope)
+	from, to := GroupVersion.String(), v1alpha5.GroupVersion.String()
+
+	out.APIVersion = to
+	out.Kind = "Bottle"
+
+	// No root level migrations
+	out.Annotations = in.Annotations
+	out.Labels = in.Labels
+	out.Description = in.Description
+
+	// migrate sources, URI => URL
+	out.Sources = make([]v1alpha5.Source, len(in.Sources))
+	for i, s := range in.Sources {
+		out.Sources[i] = v1alpha5.Source{
+			Name: s.Name,
+			URL:  s.URI,
+		}
+	}
+
+	// migrate authors -> stays the same
+	out.Authors = make([]v1alpha5.Author, len(in.Authors))
+	for i, a := range in.Authors {
+		out.Authors[i] = v1alpha5.Author(a)
+	}
+
+	// migrate metrics -> stays the same
+	out.Metrics = make([]v1alpha5.Metric, len(in.Metrics))
+	for i, m := range in.Metrics {
+		out.Metrics[i] = v1alpha5.Metric(m)
+	}
+
+	// migrate public artifacts, mediaType => type
+	// The upgrade derives the media type from the path so the media type is only kept as the (free form) type.
+	out.PublicArtifacts = make([]v1alpha5.PublicArtifact, len(in.PublicArtifacts))
+	for i, art := range in.PublicArtifacts {
+		out.PublicArtifacts[i] = v1alpha5.PublicArtifact{
+			Type:   art.MediaType,
+			Name:   art.Name,
+			Path:   art.Path,
+			Digest: art.Digest.String(),
+		}
+		if art.MediaType != "" && art.MediaType != mediatype.DetermineType(art.Path) {
+			report.Dropped(field.NewPath("publicArtifacts").Index(i).Child("mediaType"), from, to,
+				fmt.Sprintf("v1alpha5 has no media type (%q is stored as the type but will be determined from the path when upgrading)", art.MediaType))
+		}
+	}
+
+	// migrate parts, the layer information is added back in if we have a manifest
+	out.Parts = make([]v1alpha5.Part, len(in.Parts))
+	for i, p := range in.Parts {
+		out.Parts[i] = v1alpha5.Part{
+			Name:   p.Name,
+			Size:   p.Size,
+			Digest: p.Digest.String(),
+			Labels: p.Labels,
+		}
+		if manifest != nil && i < len(manifest.Layers) {
+			out.Parts[i].LayerSize = manifest.Layers[i].Size
+			out.Parts[i].LayerDigest = manifest.Layers[i].Digest.String()
+		}
+	}
+
+	return nil
+}
@@ -38,5 +38,9 @@ func addKnownConversions(scheme *runtime.Scheme) error {
 		return err
 	}
 
-	return conversion.AddConversionFuncHelper(scheme, Convert_v1alpha5_Bottle_To_v1beta1_Bottle)
+	if err := conversion.AddConversionFuncHelper(scheme, Convert_v1alpha5_Bottle_To_v1beta1_Bottle); err != nil {
+		return err
+	}
+
+	return conversion.AddConversionFuncHelper(scheme, Convert_v1beta1_Bottle_To_v1alpha5_Bottle)
 }
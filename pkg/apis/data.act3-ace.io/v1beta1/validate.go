@@ -20,6 +20,11 @@ func (p Part) Validate() error {
 		return errs.ToAggregate()
 	}
 
+	return p.validateFields()
+}
+
+// validateFields validates the Part fields other than the labels
+func (p Part) validateFields() error {
 	return validation.ValidateStruct(&p,
 		validation.Field(&p.Name, validation.Required, val.IsRelativePath, val.IsPortablePath),
 		// zero is a valid value so we cannot use the validation.Required test
@@ -63,26 +68,38 @@ func (m Metric) Validate() error {
 	)
 }
 
-// ValidateMetrics ensures that the metrics names are unique
-func ValidateMetrics(metrics []Metric) error {
+// metricIssues reports every metric whose name is not unique
+func metricIssues(fldPath *field.Path, metrics []Metric) val.IssueList {
+	var issues val.IssueList
 	// Metrics.Name is unique
 	metricNames := make(map[string]bool, len(metrics))
-	for _, m := range metrics {
+	for i, m := range metrics {
 		if _, exists := metricNames[m.Name]; exists {
-			return fmt.Errorf("metric name '%s' is not unique", m.Name)
+			issues = append(issues, val.NewIssue(fldPath.Index(i).Child("name"), val.CodeNotUnique,
+				fmt.Sprintf("metric name '%s' is not unique", m.Name)))
+			continue
 		}
 		metricNames[m.Name] = true
 	}
-	return nil
+	return issues
 }
 
-// ValidateParts ensures that the part name is unique and that no part is a prefix of any other part
-func ValidateParts(parts []Part) error {
+// ValidateMetrics ensures that the metrics names are unique
+func ValidateMetrics(metrics []Metric) error {
+	return metricIssues(field.NewPath("metrics"), metrics).Err()
+}
+
+// partIssues reports every part whose name is not unique or is a prefix of another part
+func partIssues(fldPath *field.Path, parts []Part) val.IssueList {
+	var issues val.IssueList
 	// Parts.Name is unique
 	partNames := make(map[string]bool, len(parts))
 	for i, p := range parts {
+		namePath := fldPath.Index(i).Child("name")
 		if _, exists := partNames[p.Name]; exists {
-			return fmt.Errorf("part name '%s' is not unique", p.Name)
+			issues = append(issues, val.NewIssue(namePath, val.CodeNotUnique,
+				fmt.Sprintf("part name '%s' is not unique", p.Name)))
+			continue
 		}
 		partNames[p.Name] = true
 
@@ -92,24 +109,34 @@ func ValidateParts(parts []Part) error {
 		// foo/bar
 		// foo/dog
 		for j, otherPart := range parts {
-			if i == j {
+			if i == j || otherPart.Name == p.Name {
 				continue
 			}
 			if util.IsPathPrefix(otherPart.Name, p.Name) {
-				return fmt.Errorf("part '%s' is invalid because it is a prefix of part '%s'", p.Name, otherPart.Name)
+				issues = append(issues, val.NewIssue(namePath, val.CodePartPrefix,
+					fmt.Sprintf("part '%s' is invalid because it is a prefix of part '%s'", p.Name, otherPart.Name)))
 			}
 		}
 	}
-	return nil
+	return issues
 }
 
-// ValidatePublicArtifacts validates public artifacts path is unique and that each artifact belongs to a single part
-func ValidatePublicArtifacts(b Bottle) error {
+// ValidateParts ensures that the part name is unique and that no part is a prefix of any other part
+func ValidateParts(parts []Part) error {
+	return partIssues(field.NewPath("parts"), parts).Err()
+}
+
+// publicArtifactIssues reports every public artifact whose path is not unique or does not belong to a single part
+func publicArtifactIssues(fldPath *field.Path, b Bottle) val.IssueList {
+	var issues val.IssueList
 	// PublicArtifacts.Path is unique
-	artifactPaths := make(map[string]bool, len(b.Parts))
-	for _, a := range b.PublicArtifacts {
+	artifactPaths := make(map[string]bool, len(b.PublicArtifacts))
+	for i, a := range b.PublicArtifacts {
+		pathPath := fldPath.Index(i).Child("path")
 		if _, exists := artifactPaths[a.Path]; exists {
-			return fmt.Errorf("public artifact path '%s' is not unique", a.Path)
+			issues = append(issues, val.NewIssue(pathPath, val.CodeNotUnique,
+				fmt.Sprintf("public artifact path '%s' is not unique", a.Path)))
+			continue
 		}
 		artifactPaths[a.Path] = true
 
@@ -121,14 +148,21 @@ func ValidatePublicArtifacts(b Bottle) error {
 			}
 		}
 		if enclosingParts == 0 {
-			return fmt.Errorf("public artifact path '%s' is not in any part", a.Path)
+			issues = append(issues, val.NewIssue(pathPath, val.CodeArtifactNotInPart,
+				fmt.Sprintf("public artifact path '%s' is not in any part", a.Path)))
 		}
 		if enclosingParts > 1 {
 			// This might not be possible given the requirements on parts.Name
-			return fmt.Errorf("public artifact path '%s' is in more multiple parts (the parts are specified incorrectly)", a.Path)
+			issues = append(issues, val.NewIssue(pathPath, val.CodeArtifactInMultipleParts,
+				fmt.Sprintf("public artifact path '%s' is in more multiple parts (the parts are specified incorrectly)", a.Path)))
 		}
 	}
-	return nil
+	return issues
+}
+
+// ValidatePublicArtifacts validates public artifacts path is unique and that each artifact belongs to a single part
+func ValidatePublicArtifacts(b Bottle) error {
+	return publicArtifactIssues(field.NewPath("publicArtifacts"), b).Err()
 }
 
 // Validate Bottle using ozzo-validation. Returns a list of errors
@@ -163,3 +197,39 @@ func (b Bottle) Validate() error {
 		validation.Field(&b.Parts),
 	)
 }
+
+// ValidationReport validates the bottle and returns a report listing every problem found, each with the path to the
+// offending field.  Use Report.Err() to determine if the bottle is valid.
+func (b Bottle) ValidationReport() *val.Report {
+	report := &val.Report{}
+	report.AddError(field.NewPath("apiVersion"), validation.Validate(b.APIVersion, validation.Required, validation.In(GroupVersion.String())))
+	report.AddError(field.NewPath("kind"), validation.Validate(b.Kind, validation.Required, validation.In("Bottle")))
+	report.Add(val.LabelIssues(field.NewPath("labels"), b.Labels)...)
+	report.Add(val.AnnotationIssues(field.NewPath("annotations"), b.Annotations)...)
+
+	for i, s := range b.Sources {
+		report.AddError(field.NewPath("sources").Index(i), s.Validate())
+	}
+
+	for i, a := range b.Authors {
+		report.AddError(field.NewPath("authors").Index(i), a.Validate())
+	}
+
+	for i, m := range b.Metrics {
+		report.AddError(field.NewPath("metrics").Index(i), m.Validate())
+	}
+	report.Add(metricIssues(field.NewPath("metrics"), b.Metrics)...)
+
+	for i, a := range b.PublicArtifacts {
+		report.AddError(field.NewPath("publicArtifacts").Index(i), a.Validate())
+	}
+	report.Add(publicArtifactIssues(field.NewPath("publicArtifacts"), b)...)
+
+	for i, p := range b.Parts {
+		report.Add(val.LabelIssues(field.NewPath("parts").Index(i).Child("labels"), p.Labels)...)
+		report.AddError(field.NewPath("parts").Index(i), p.validateFields())
+	}
+	report.Add(partIssues(field.NewPath("parts"), b.Parts)...)
+
+	return report
+}
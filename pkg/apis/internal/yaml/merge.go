@@ -0,0 +1,192 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeNode updates dst in place so that it holds the same value as src while keeping as much of dst (comments,
+// key ordering and styles) as possible.  The returned node must be used in place of dst since it is replaced when
+// the kinds of the nodes differ.
+// Mapping keys that exist in both are merged recursively, new keys are appended and missing keys are removed.
+// Sequence elements are merged by index.
+func MergeNode(dst, src *yaml.Node) *yaml.Node {
+	if dst == nil {
+		return src
+	}
+	if equalNodes(dst, src) {
+		return dst
+	}
+
+	wasEmpty := len(dst.Content) == 0
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		content := make([]*yaml.Node, 0, len(src.Content))
+		// existing keys keep their position
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if _, v := MappingValue(src, dst.Content[i].Value); v != nil {
+				content = append(content, dst.Content[i], MergeNode(dst.Content[i+1], v))
+			}
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if _, v := MappingValue(dst, src.Content[i].Value); v == nil {
+				content = append(content, src.Content[i], src.Content[i+1])
+			}
+		}
+		dst.Content = content
+		clearEmptyFlowStyle(dst, src, wasEmpty)
+		return dst
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		content := make([]*yaml.Node, len(src.Content))
+		for i, n := range src.Content {
+			if i < len(dst.Content) {
+				content[i] = MergeNode(dst.Content[i], n)
+			} else {
+				content[i] = n
+			}
+		}
+		dst.Content = content
+		clearEmptyFlowStyle(dst, src, wasEmpty)
+		return dst
+
+	case dst.Kind == yaml.ScalarNode && src.Kind == yaml.ScalarNode:
+		dst.Value = src.Value
+		dst.Tag = src.Tag
+		dst.Style = src.Style
+		return dst
+
+	default:
+		// different kinds so the value is replaced but the comments are kept
+		src.HeadComment = dst.HeadComment
+		src.LineComment = dst.LineComment
+		src.FootComment = dst.FootComment
+		return src
+	}
+}
+
+// MappingValue returns the key and value nodes for key in the mapping node m (nil if not found)
+func MappingValue(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// DetectIndent returns the indentation (number of spaces) used by the YAML document in data.
+// It returns 0 if the document has no indented lines.
+func DetectIndent(data []byte) int {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		trimmed := bytes.TrimLeft(line, " ")
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if n := len(line) - len(trimmed); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// equalNodes returns true if the nodes decode to the same value
+func equalNodes(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	var va, vb any
+	if err := a.Decode(&va); err != nil {
+		return false
+	}
+	if err := b.Decode(&vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// clearEmptyFlowStyle switches dst from the empty flow style (e.g., "{}" or "[]") to the style of src once it has content
+func clearEmptyFlowStyle(dst, src *yaml.Node, wasEmpty bool) {
+	if wasEmpty && dst.Style&yaml.FlowStyle != 0 && len(dst.Content) > 0 {
+		dst.Style = src.Style
+	}
+}
+
+// PreserveBlankLines adds a leading newline to the head comment of the mapping keys and sequence items under n that
+// are preceded by a blank line in data (the source of n).  The YAML encoder otherwise drops the blank lines that
+// separate sections of a document.
+func PreserveBlankLines(data []byte, n *yaml.Node) {
+	lines := bytes.Split(data, []byte("\n"))
+	var walk func(n *yaml.Node, isItem bool)
+	walk = func(n *yaml.Node, isItem bool) {
+		if isItem {
+			start := n.Line
+			if n.HeadComment != "" {
+				start -= bytes.Count([]byte(n.HeadComment), []byte("\n")) + 1
+			}
+			// lines are 1-based so the previous line is at index start-2
+			if start >= 2 && start-2 < len(lines) && len(bytes.TrimSpace(lines[start-2])) == 0 {
+				n.HeadComment = "\n" + n.HeadComment
+			}
+		}
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, false)
+				// the encoder always separates the document's head comment from the first key with a blank line
+				if n.HeadComment != "" && c.Kind == yaml.MappingNode && len(c.Content) > 0 {
+					c.Content[0].HeadComment = strings.TrimPrefix(c.Content[0].HeadComment, "\n")
+				}
+			}
+		case yaml.MappingNode:
+			for i, c := range n.Content {
+				walk(c, i%2 == 0)
+			}
+		case yaml.SequenceNode:
+			for _, c := range n.Content {
+				walk(c, true)
+			}
+		}
+	}
+	walk(n, false)
+}
+
+// Encode writes the YAML document n to w using the indentation indent (0 for the default).
+// The encoder always adds a blank line after a foot comment so a leading blank line (see PreserveBlankLines) on the
+// head comment of the following mapping key is removed while encoding.
+func Encode(w io.Writer, n *yaml.Node, indent int) error {
+	var trimmed []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		for i, c := range n.Content {
+			if n.Kind == yaml.MappingNode && i >= 2 && i%2 == 0 && n.Content[i-2].FootComment != "" &&
+				strings.HasPrefix(c.HeadComment, "\n") {
+				c.HeadComment = c.HeadComment[1:]
+				trimmed = append(trimmed, c)
+			}
+			walk(c)
+		}
+	}
+	walk(n)
+	defer func() {
+		for _, c := range trimmed {
+			c.HeadComment = "\n" + c.HeadComment
+		}
+	}()
+
+	enc := yaml.NewEncoder(w)
+	if indent > 0 {
+		enc.SetIndent(indent)
+	}
+	if err := enc.Encode(n); err != nil {
+		return err
+	}
+	return enc.Close()
+}
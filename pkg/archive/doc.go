@@ -0,0 +1,4 @@
+// Package archive provides reproducible archives of the directory parts of a bottle.
+// The digest of a directory part is the digest of its archive so archiving the same directory must produce identical
+// bytes on any machine.
+package archive
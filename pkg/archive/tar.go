@@ -0,0 +1,104 @@
+package archive
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+)
+
+const (
+	// dirMode is the permission used for all directories in the archive
+	dirMode = 0o755
+
+	// fileMode is the permission used for files in the archive that are not executable
+	fileMode = 0o644
+
+	// execMode is the permission used for files in the archive that are executable by anyone
+	execMode = 0o755
+)
+
+// epoch is the modification time of every entry in the archive
+var epoch = time.Unix(0, 0).UTC()
+
+// Tar writes a reproducible tar archive of the directory dir in fsys to w.
+// Paths in the archive are relative to dir (the part name is not included).
+// Entries are written in lexical order (directories before their contents) and the metadata that differs between
+// machines is normalized: modification times are set to the Unix epoch, user and group IDs and names are removed, and
+// permissions are reduced to 0755 for directories and executable files and 0644 for other files.
+// The PAX format is used so long and non-ASCII names are encoded the same way everywhere.
+// Symbolic links are followed for files; links to directories and other special files are an error.
+func Tar(fsys fs.FS, dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		name := p
+		if dir != "." {
+			name = p[len(dir)+1:]
+		}
+
+		info, err := fs.Stat(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		hdr := &tar.Header{
+			Name:    name,
+			ModTime: epoch,
+			Format:  tar.FormatPAX,
+		}
+		switch {
+		case d.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			hdr.Mode = dirMode
+		case info.Mode().IsRegular():
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = info.Size()
+			hdr.Mode = fileMode
+			if info.Mode().Perm()&0o111 != 0 {
+				hdr.Mode = execMode
+			}
+		case info.IsDir():
+			return fmt.Errorf("%s: symbolic links to directories are not supported", path.Join(dir, name))
+		default:
+			return fmt.Errorf("%s: unsupported file type %s", path.Join(dir, name), info.Mode().Type())
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("writing tar header for %s: %w", name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+		return copyFile(tw, fsys, p, hdr.Size)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// copyFile writes the content of the file p (which must have the given size) to tw
+func copyFile(tw *tar.Writer, fsys fs.FS, p string, size int64) error {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(tw, f)
+	if err != nil {
+		return fmt.Errorf("archiving %s: %w", p, err)
+	}
+	if n != size {
+		return fmt.Errorf("archiving %s: file changed size while archiving", p)
+	}
+	return nil
+}
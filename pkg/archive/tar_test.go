@@ -0,0 +1,70 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTar(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	longName := "data/" + string(bytes.Repeat([]byte("x"), 120)) + "/naïve.txt"
+	fsys := fstest.MapFS{
+		"data/b.txt":    &fstest.MapFile{Data: []byte("b"), Mode: 0o600, ModTime: time.Now()},
+		"data/a/run.sh": &fstest.MapFile{Data: []byte("#!/bin/sh"), Mode: 0o700},
+		"data/.hidden":  &fstest.MapFile{Data: []byte("hidden")},
+		"data/empty":    &fstest.MapFile{Mode: fs.ModeDir | 0o700},
+		longName:        &fstest.MapFile{Data: []byte("long")},
+		"other/ignored": &fstest.MapFile{Data: []byte("ignored")},
+		"data/pipe":     &fstest.MapFile{Mode: fs.ModeNamedPipe},
+	}
+	assert.ErrorContains(Tar(fsys, "data", io.Discard), "unsupported file type")
+	delete(fsys, "data/pipe")
+
+	buf := &bytes.Buffer{}
+	require.NoError(Tar(fsys, "data", buf))
+
+	// the metadata does not change the archive
+	fsys["data/b.txt"].ModTime = time.Now().Add(time.Hour)
+	fsys["data/b.txt"].Mode = 0o644
+	buf2 := &bytes.Buffer{}
+	require.NoError(Tar(fsys, "data", buf2))
+	assert.Equal(buf.Bytes(), buf2.Bytes())
+
+	type entry struct {
+		name string
+		mode int64
+	}
+	var entries []entry
+	tr := tar.NewReader(buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(err)
+		assert.True(hdr.ModTime.Equal(time.Unix(0, 0)))
+		assert.Zero(hdr.Uid)
+		assert.Zero(hdr.Gid)
+		assert.Empty(hdr.Uname)
+		entries = append(entries, entry{hdr.Name, hdr.Mode})
+	}
+	assert.Equal([]entry{
+		{".hidden", 0o644},
+		{"a/", 0o755},
+		{"a/run.sh", 0o755},
+		{"b.txt", 0o644},
+		{"empty/", 0o755},
+		{longName[len("data/") : len(longName)-len("naïve.txt")], 0o755},
+		{longName[len("data/"):], 0o644},
+	}, entries)
+}
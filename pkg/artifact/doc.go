@@ -0,0 +1,4 @@
+// Package artifact extracts public artifacts from the layers of a bottle.
+// Public artifacts may be inside of directory parts so only the enclosing layer is streamed (and only up to the end of
+// the artifact) instead of unpacking the whole part.
+package artifact
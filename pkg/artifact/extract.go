@@ -0,0 +1,113 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/layer"
+)
+
+var (
+	// ErrNotFound is returned when the path is not a public artifact of the bottle
+	ErrNotFound = errors.New("public artifact not found")
+
+	// ErrTooLarge is returned when the public artifact is not smaller than v1.MaxPublicArtifactSize
+	ErrTooLarge = errors.New("public artifact is too large")
+
+	// ErrDigestMismatch is returned when the content of the public artifact does not match its digest
+	ErrDigestMismatch = errors.New("public artifact digest mismatch")
+)
+
+// BlobSource provides the blobs of a bottle (e.g., from a registry or an OCI image layout)
+type BlobSource interface {
+	// Fetch returns the content of the blob described by desc.  The caller must close it.
+	Fetch(ctx context.Context, desc ocispecv1.Descriptor) (io.ReadCloser, error)
+}
+
+// BlobSourceFunc is an adapter to allow the use of an ordinary function as a BlobSource
+type BlobSourceFunc func(ctx context.Context, desc ocispecv1.Descriptor) (io.ReadCloser, error)
+
+// Fetch calls f(ctx, desc)
+func (f BlobSourceFunc) Fetch(ctx context.Context, desc ocispecv1.Descriptor) (io.ReadCloser, error) {
+	return f(ctx, desc)
+}
+
+// Extract returns the content of the public artifact with the path artifactPath.
+// The layer of the enclosing part is fetched from src and only the artifact is read from it.
+// The content is verified against the artifact's digest and must be smaller than v1.MaxPublicArtifactSize.
+func Extract(ctx context.Context, bottle *v1.Bottle, manifest *ocispecv1.Manifest, src BlobSource, artifactPath string) ([]byte, error) {
+	var artifact *v1.PublicArtifact
+	for i := range bottle.PublicArtifacts {
+		if bottle.PublicArtifacts[i].Path == artifactPath {
+			artifact = &bottle.PublicArtifacts[i]
+			break
+		}
+	}
+	if artifact == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, artifactPath)
+	}
+	if err := artifact.Digest.Validate(); err != nil {
+		return nil, fmt.Errorf("public artifact %q: %w", artifactPath, err)
+	}
+
+	if len(manifest.Layers) != len(bottle.Parts) {
+		return nil, fmt.Errorf("number of parts (%d) is not equal to the number of layers (%d)", len(bottle.Parts), len(manifest.Layers))
+	}
+	index := -1
+	for i, part := range bottle.Parts {
+		if _, ok := layer.PartPath(part.Name, artifactPath); ok {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("public artifact %q is not in any part", artifactPath)
+	}
+	part := bottle.Parts[index]
+	desc := manifest.Layers[index]
+
+	codec, err := layer.Lookup(desc.MediaType)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return nil, fmt.Errorf("fetching layer of part %q: %w", part.Name, err)
+	}
+	defer rc.Close()
+
+	buf := &limitedBuffer{limit: v1.MaxPublicArtifactSize - 1}
+	if err := codec.ExtractFile(rc, part.Name, artifactPath, buf); err != nil {
+		return nil, fmt.Errorf("extracting public artifact %q from part %q: %w", artifactPath, part.Name, err)
+	}
+	data := buf.Bytes()
+	if dgst := artifact.Digest.Algorithm().FromBytes(data); dgst != artifact.Digest {
+		return nil, fmt.Errorf("%w: %q has digest %s but its content has digest %s", ErrDigestMismatch, artifactPath, artifact.Digest, dgst)
+	}
+	return data, nil
+}
+
+// limitedBuffer is a buffer that returns ErrTooLarge when more than limit bytes are written.
+// The bytes.Buffer is not embedded so io.Copy cannot bypass the limit with ReadFrom.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("%w: it must be smaller than %d bytes", ErrTooLarge, v1.MaxPublicArtifactSize)
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the content written to the buffer
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package artifact
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/layer"
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+)
+
+func TestExtract(t *testing.T) {
+	assert := assert.New(t)
+
+	big := bytes.Repeat([]byte("x"), v1.MaxPublicArtifactSize)
+	fsys := fstest.MapFS{
+		"data/README.md":   {Data: []byte("# Data\n")},
+		"data/results.csv": {Data: []byte("accuracy\n0.9\n")},
+		"data/big.bin":     {Data: big},
+		"model.onnx":       {Data: []byte("not really a model")},
+	}
+	bottle, err := v1.FromDirectory(fsys, v1.DirectoryOptions{})
+	require.NoError(t, err)
+	bottle.PublicArtifacts = []v1.PublicArtifact{
+		{Name: "Readme", Path: "data/README.md", MediaType: "text/markdown", Digest: digest.FromString("# Data\n")},
+		{Name: "Results", Path: "data/results.csv", MediaType: "text/csv", Digest: digest.FromString("wrong")},
+		{Name: "Big", Path: "data/big.bin", MediaType: "application/octet-stream", Digest: digest.FromBytes(big)},
+		{Name: "Model", Path: "model.onnx", MediaType: "application/octet-stream", Digest: digest.FromString("not really a model")},
+	}
+
+	blobs := map[digest.Digest][]byte{}
+	var layers []v1.LayerInfo
+	for _, part := range bottle.Parts {
+		mediaType := mediatype.MediaTypeLayerZstd
+		if part.Name == "data/" {
+			mediaType = mediatype.MediaTypeLayerTarGzip
+		}
+		codec, err := layer.Lookup(mediaType)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+		require.NoError(t, codec.Encode(fsys, part.Name, buf))
+		dgst := digest.FromBytes(buf.Bytes())
+		blobs[dgst] = buf.Bytes()
+		layers = append(layers, v1.LayerInfo{MediaType: mediaType, Digest: dgst, Size: int64(buf.Len())})
+	}
+	manifest, err := v1.BuildManifest(*bottle, layers, v1.ManifestOptions{})
+	require.NoError(t, err)
+
+	src := BlobSourceFunc(func(_ context.Context, desc ocispecv1.Descriptor) (io.ReadCloser, error) {
+		data, ok := blobs[desc.Digest]
+		if !ok {
+			return nil, fmt.Errorf("blob %s: %w", desc.Digest, os.ErrNotExist)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	ctx := context.Background()
+
+	data, err := Extract(ctx, bottle, manifest, src, "data/README.md")
+	assert.NoError(err)
+	assert.Equal("# Data\n", string(data))
+
+	data, err = Extract(ctx, bottle, manifest, src, "model.onnx")
+	assert.NoError(err)
+	assert.Equal("not really a model", string(data))
+
+	_, err = Extract(ctx, bottle, manifest, src, "data/results.csv")
+	assert.ErrorIs(err, ErrDigestMismatch)
+
+	_, err = Extract(ctx, bottle, manifest, src, "data/big.bin")
+	assert.ErrorIs(err, ErrTooLarge)
+
+	_, err = Extract(ctx, bottle, manifest, src, "data/other.txt")
+	assert.ErrorIs(err, ErrNotFound)
+
+	delete(blobs, manifest.Layers[1].Digest)
+	_, err = Extract(ctx, bottle, manifest, src, "model.onnx")
+	assert.ErrorIs(err, os.ErrNotExist)
+}
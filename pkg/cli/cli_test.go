@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oldBottle = `
+apiVersion: data.act3-ace.io/v1alpha5
+kind: Bottle
+description: MNIST Dataset
+authors:
+  - name: John Smith
+    email: john.smith@example.com
+publicArtifacts:
+  - name: Some text
+    type: text/plain
+    path: foo/sample.txt
+    digest: sha256:eab4fe92c4c81e25676d91b3dac3191fe3d0a22e2a6644b76726a7683862a339
+parts:
+  - name: foo
+    size: 45
+    digest: sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae
+`
+
+const alpha2Bottle = `{
+  "apiVersion": "data.act3-ace.io/v1alpha2",
+  "kind": "Bottle",
+  "description": "This is a v1alpha2.",
+  "maintainers": [{"name": "Jane Smith", "email": "jane.smith@example.com"}],
+  "files": [
+    {"name": "foo/bar", "size": 45, "digest": {"sha256": "9a1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}}
+  ]
+}`
+
+const mismatchBottle = `
+apiVersion: data.act3-ace.io/v1
+kind: Bottle
+description: MNIST Dataset
+authors:
+  - name: John Smith
+    email: john.smith@example.com
+publicArtifacts:
+  - name: Some text
+    mediaType: text/html
+    path: foo/sample.txt
+    digest: sha256:eab4fe92c4c81e25676d91b3dac3191fe3d0a22e2a6644b76726a7683862a339
+parts:
+  - name: foo/
+    size: 45
+    digest: sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae
+`
+
+const invalidBottle = `{
+  "apiVersion": "data.act3-ace.io/v1",
+  "kind": "Bottle",
+  "parts": [
+    {"name": "foo", "size": 1, "digest": "sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"},
+    {"name": "foo", "size": 1, "digest": "sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae"}
+  ]
+}`
+
+// run runs the command and returns stdout, stderr and the exit code
+func run(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := NewRootCmd()
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), ExitCode(err)
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(p, []byte(data), 0o644))
+	return p
+}
+
+func TestInit(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "entry.yaml")
+	_, _, code := run(t, "init", p)
+	assert.Equal(t, ExitOK, code)
+
+	_, stderr, code := run(t, "init", p)
+	assert.Equal(t, ExitError, code)
+	assert.Contains(t, stderr, "already exists")
+
+	_, _, code = run(t, "init", "--force", p)
+	assert.Equal(t, ExitOK, code)
+
+	// the template is valid
+	stdout, _, code := run(t, "validate", p)
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "valid (data.act3-ace.io/v1, 0 issues)")
+}
+
+func TestValidate(t *testing.T) {
+	stdout, _, code := run(t, "validate", "-o", "json", writeFile(t, "entry.json", invalidBottle))
+	assert.Equal(t, ExitFailed, code)
+	var result checkResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.False(t, result.Valid)
+	assert.Equal(t, "data.act3-ace.io/v1", result.APIVersion)
+	assert.NotEmpty(t, result.Issues)
+
+	stdout, _, code = run(t, "validate", writeFile(t, "entry.yaml", oldBottle))
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "valid (data.act3-ace.io/v1alpha5")
+
+	_, _, code = run(t, "validate", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Equal(t, ExitError, code)
+
+	_, _, code = run(t, "validate", "--layers", writeFile(t, "entry.yaml", oldBottle))
+	assert.Equal(t, ExitError, code)
+
+	_, _, code = run(t, "validate", "-o", "xml", writeFile(t, "entry.yaml", oldBottle))
+	assert.Equal(t, ExitError, code)
+}
+
+func TestLint(t *testing.T) {
+	lintCodes := func(data string) []string {
+		t.Helper()
+		stdout, _, code := run(t, "lint", "-o", "json", writeFile(t, "entry.yaml", data))
+		assert.NotEqual(t, ExitError, code)
+		var result checkResult
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		var codes []string
+		for _, issue := range result.Issues {
+			codes = append(codes, issue.Code)
+		}
+		return codes
+	}
+
+	codes := lintCodes(oldBottle)
+	assert.Contains(t, codes, CodeOldVersion)
+	assert.NotContains(t, codes, CodeLossyConversion)
+	assert.NotContains(t, codes, CodeNoDescription)
+	assert.NotContains(t, codes, CodeNoAuthors)
+
+	codes = lintCodes(alpha2Bottle)
+	assert.Contains(t, codes, CodeOldVersion)
+	assert.Contains(t, codes, CodeLossyConversion)
+
+	codes = lintCodes(mismatchBottle)
+	assert.Equal(t, []string{CodeMediaTypeMismatch}, codes)
+
+	codes = lintCodes(`{"apiVersion": "data.act3-ace.io/v1", "kind": "Bottle", "description": "d", "authors": [{"name": "n", "email": "n@example.com"}],
+		"annotations": {"bottle.data.act3-ace.io/metrics": "{\"loss\": {\"unit\": \"furlong\"}}"}, "metrics": [{"name": "loss", "value": "1"}]}`)
+	assert.Equal(t, []string{"validation_invalid"}, codes)
+
+	codes = lintCodes(`{"apiVersion": "data.act3-ace.io/v1", "kind": "Bottle"}`)
+	assert.Equal(t, []string{CodeNoDescription, CodeNoAuthors}, codes)
+
+	_, _, code := run(t, "lint", writeFile(t, "entry.yaml", oldBottle))
+	assert.Equal(t, ExitOK, code)
+	_, _, code = run(t, "lint", "--strict", writeFile(t, "entry.yaml", oldBottle))
+	assert.Equal(t, ExitFailed, code)
+}
+
+func TestConvert(t *testing.T) {
+	stdout, stderr, code := run(t, "convert", writeFile(t, "entry.json", alpha2Bottle))
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "apiVersion: data.act3-ace.io/v1\n")
+	assert.Contains(t, stderr, "parts[0].size: recompute")
+
+	p := writeFile(t, "entry.yaml", oldBottle)
+	stdout, _, code = run(t, "convert", p)
+	assert.Equal(t, ExitOK, code)
+
+	// the converted document has the bottle ID of the converted bottle (not of the original)
+	converted := writeFile(t, "entry.v1.yaml", stdout)
+	id, _, code := run(t, "id", "--converted", p)
+	assert.Equal(t, ExitOK, code)
+	convertedID, _, _ := run(t, "id", converted)
+	assert.Equal(t, id, convertedID)
+	originalID, _, _ := run(t, "id", p)
+	assert.NotEqual(t, originalID, convertedID)
+
+	stdout, _, code = run(t, "convert", "--to", "v1beta1", "--format", "json", converted)
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, `"apiVersion": "data.act3-ace.io/v1beta1"`)
+
+	_, _, code = run(t, "convert", "--to", "v2", p)
+	assert.Equal(t, ExitError, code)
+}
+
+func TestID(t *testing.T) {
+	p := writeFile(t, "entry.yaml", oldBottle)
+	stdout, _, code := run(t, "id", "-o", "json", "--algorithm", "sha512", p)
+	assert.Equal(t, ExitOK, code)
+	var result idResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	assert.Equal(t, "sha512", result.BottleID.Algorithm().String())
+	assert.Equal(t, "data.act3-ace.io/v1alpha5", result.APIVersion)
+	assert.False(t, result.Converted)
+
+	_, _, code = run(t, "id", "--algorithm", "md5", p)
+	assert.Equal(t, ExitError, code)
+}
+
+func TestStrictDecoding(t *testing.T) {
+	p := writeFile(t, "entry.yaml", `apiVersion: data.act3-ace.io/v1
+kind: Bottle
+descripton: MNIST
+authors:
+  - name: John Smith
+    email: john.smith@example.com
+publicArtifacts:
+  - name: Some text
+    mediaType: text/plain
+    path: foo/sample.txt
+    digest: sha256:eab4fe92c4c81e25676d91b3dac3191fe3d0a22e2a6644b76726a7683862a339
+parts:
+  - name: foo/
+    size: 45
+    digest: sha256:0b1de4364cfd94d75e7bda5d0583bcb136d6437c88a36dc06bcd64566a3530ae
+`)
+	_, _, code := run(t, "validate", p)
+	assert.Equal(t, ExitOK, code)
+
+	stdout, _, code := run(t, "validate", "--strict", p)
+	assert.Equal(t, ExitFailed, code)
+	assert.Contains(t, stdout, `descripton: line 3, column 1: unknown field (did you mean "description"?) [decode_unknown_field]`)
+
+	stdout, _, code = run(t, "lint", "-o", "json", p)
+	assert.Equal(t, ExitOK, code)
+	var result checkResult
+	require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+	require.Len(t, result.Issues, 2)
+	assert.Equal(t, "descripton", result.Issues[0].Field)
+	assert.Equal(t, DecodeCodePrefix+"unknown_field", result.Issues[0].Code)
+	assert.Equal(t, CodeNoDescription, result.Issues[1].Code)
+
+	// the located problems are reported even when the bottle cannot be decoded
+	stdout, _, code = run(t, "validate", "--strict", writeFile(t, "entry.yaml", `apiVersion: data.act3-ace.io/v1
+kind: Bottle
+parts:
+  - name: foo
+    size: big
+`))
+	assert.Equal(t, ExitFailed, code)
+	assert.Contains(t, stdout, "parts[0].size: line 5, column 11: expected an integer but found a string")
+}
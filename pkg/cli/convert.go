@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	bottle "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io"
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
+)
+
+// Formats of the converted document
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+func newConvertCmd() *cobra.Command {
+	var to, format, manifestFile string
+	cmd := &cobra.Command{
+		Use:   "convert FILE",
+		Short: "Convert a bottle metadata file to another API version",
+		Long: `Convert a bottle metadata file (entry.yaml or JSON of any version, "-" for stdin) to another API version.
+The converted document is written to stdout and the changes that lose information are written to stderr.`,
+		Example: `# Upgrade an old entry.yaml to the latest version
+bottle-schema convert --to v1 entry.yaml > entry.v1.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != formatYAML && format != formatJSON {
+				return fmt.Errorf("unsupported format %q (use %q or %q)", format, formatYAML, formatJSON)
+			}
+			manifest, err := readManifest(cmd, manifestFile)
+			if err != nil {
+				return err
+			}
+			doc, err := readDocument(cmd, args[0], manifest)
+			if err != nil {
+				return err
+			}
+
+			scheme, err := bottle.Scheme()
+			if err != nil {
+				return err
+			}
+			gvk := schema.GroupVersionKind{Group: v1.GroupVersion.Group, Version: to, Kind: "Bottle"}
+			if gv, err := schema.ParseGroupVersion(to); err == nil && gv.Group != "" {
+				gvk = gv.WithKind("Bottle")
+			}
+			out, err := scheme.New(gvk)
+			if err != nil {
+				return fmt.Errorf("unsupported API version %q: %w", to, err)
+			}
+			changes := doc.Changes
+			if gvk.GroupVersion() == v1.GroupVersion {
+				out = doc.Bottle
+			} else {
+				report := &migrate.Report{}
+				if err := scheme.Convert(doc.Bottle, out, &migrate.Context{Manifest: manifest, Report: report}); err != nil {
+					return fmt.Errorf("converting to %s: %w", gvk.GroupVersion(), err)
+				}
+				changes = append(changes, report.Changes...)
+			}
+			out.GetObjectKind().SetGroupVersionKind(gvk)
+
+			var data []byte
+			if format == formatJSON {
+				data, err = json.MarshalIndent(out, "", "  ")
+				data = append(data, '\n')
+			} else {
+				data, err = yaml.Marshal(out)
+			}
+			if err != nil {
+				return fmt.Errorf("encoding bottle: %w", err)
+			}
+			if _, err := cmd.OutOrStdout().Write(data); err != nil {
+				return err
+			}
+			for _, c := range changes {
+				if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s (%s to %s): %s\n", c.Field, c.Kind, c.From, c.To, c.Message); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", v1.GroupVersion.Version, "API version to convert to (e.g., v1 or v1beta1)")
+	cmd.Flags().StringVar(&format, "format", formatYAML, "Format of the converted document (yaml or json)")
+	cmd.Flags().StringVarP(&manifestFile, "manifest", "m", "", "OCI manifest (JSON) of the bottle, used to fill in fields that old versions do not have")
+	return cmd
+}
@@ -0,0 +1,5 @@
+// Package cli provides the bottle-schema command line interface.
+// The commands validate, lint, convert and identify bottle metadata files (entry.yaml or JSON of any version) and
+// create new ones.  Commands that check a bottle support JSON output and exit with a non-zero code when the bottle
+// fails the check so they can gate bottle pushes in CI pipelines (see ExitCode).
+package cli
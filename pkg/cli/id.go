@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/util"
+)
+
+// idResult is the JSON output of the id command
+type idResult struct {
+	// BottleID is the digest of the canonical bottle config
+	BottleID digest.Digest `json:"bottleID"`
+
+	// APIVersion of the file (before it was converted to v1)
+	APIVersion string `json:"apiVersion"`
+
+	// Converted is set when the bottle ID is of the bottle converted to v1
+	Converted bool `json:"converted,omitempty"`
+}
+
+func newIDCmd(opts *options) *cobra.Command {
+	var algorithm string
+	var converted bool
+	cmd := &cobra.Command{
+		Use:   "id FILE",
+		Short: "Compute the bottle ID of a bottle metadata file",
+		Long: `Compute the bottle ID (the digest of the canonical bottle config) of a bottle metadata file (entry.yaml or JSON of
+any version, "-" for stdin).  The bottle ID is of the file's own version unless --converted is given, in which case it
+is the bottle ID the bottle has once converted to the latest version.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := readDocument(cmd, args[0], nil)
+			if err != nil {
+				return err
+			}
+			alg := digest.Algorithm(algorithm)
+			var id digest.Digest
+			if converted || doc.OriginalVersion == v1.GroupVersion {
+				id, err = doc.Bottle.BottleID(alg)
+			} else {
+				id, err = originalBottleID(doc.Original, alg)
+			}
+			if err != nil {
+				return err
+			}
+			if opts.output == outputJSON {
+				return writeJSON(cmd.OutOrStdout(), idResult{
+					BottleID:   id,
+					APIVersion: doc.OriginalVersion.String(),
+					Converted:  converted && doc.OriginalVersion != v1.GroupVersion,
+				})
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), id)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&algorithm, "algorithm", string(digest.Canonical), "Digest algorithm of the bottle ID (sha256, sha384 or sha512)")
+	cmd.Flags().BoolVar(&converted, "converted", false, "Compute the bottle ID of the bottle converted to the latest version")
+	return cmd
+}
+
+// originalBottleID returns the digest of the canonical config of a bottle in its own (older) version
+func originalBottleID(obj runtime.Object, alg digest.Algorithm) (digest.Digest, error) {
+	if !alg.Available() {
+		return "", fmt.Errorf("digest algorithm %q is not available", alg)
+	}
+	data, err := util.CanonicalJSON(obj)
+	if err != nil {
+		return "", fmt.Errorf("encoding bottle config: %w", err)
+	}
+	return alg.FromBytes(data), nil
+}
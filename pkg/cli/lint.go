@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"mime"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	bottle "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io"
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/metric"
+	"github.com/act3-ai/bottle-schema/pkg/migrate"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// Codes of the warnings produced by the lint command (in addition to the validation issues)
+const (
+	// CodeOldVersion is used when the file is not in the latest API version
+	CodeOldVersion = "lint_old_version"
+
+	// CodeLossyConversion is used when converting the file to the latest API version loses information
+	CodeLossyConversion = "lint_lossy_conversion"
+
+	// CodeNoDescription is used when the bottle has no description
+	CodeNoDescription = "lint_no_description"
+
+	// CodeNoAuthors is used when the bottle has no authors
+	CodeNoAuthors = "lint_no_authors"
+
+	// CodeMediaTypeMismatch is used when the media type of a public artifact is not the one expected from its path
+	CodeMediaTypeMismatch = "lint_media_type_mismatch"
+)
+
+// lint returns the warnings for good practices that the bottle does not follow
+func lint(doc *bottle.LoadResult) val.IssueList {
+	var issues val.IssueList
+	if gv := doc.OriginalVersion; gv != v1.GroupVersion {
+		issues = append(issues, val.NewWarning(field.NewPath("apiVersion"), CodeOldVersion,
+			"the API version "+gv.String()+" is outdated, convert the file to "+v1.GroupVersion.String()))
+	}
+	for _, c := range doc.Changes {
+		if c.Kind == migrate.ChangeDefaulted {
+			continue
+		}
+		issue := val.NewWarning(nil, CodeLossyConversion, string(c.Kind)+" when converting from "+c.From+": "+c.Message)
+		issue.Field = c.Field
+		issues = append(issues, issue)
+	}
+
+	b := doc.Bottle
+	if b.Description == "" {
+		issues = append(issues, val.NewWarning(field.NewPath("description"), CodeNoDescription,
+			"the bottle should have a description"))
+	}
+	if len(b.Authors) == 0 {
+		issues = append(issues, val.NewWarning(field.NewPath("authors"), CodeNoAuthors,
+			"the bottle should have at least one author"))
+	}
+	issues = append(issues, metric.Issues(*b)...)
+	for i, a := range b.PublicArtifacts {
+		expected := mediatype.DetermineType(a.Path)
+		if expected == "" || a.MediaType == "" {
+			continue
+		}
+		if baseMediaType(expected) != baseMediaType(a.MediaType) {
+			issues = append(issues, val.NewWarning(field.NewPath("publicArtifacts").Index(i).Child("mediaType"),
+				CodeMediaTypeMismatch, "media type "+a.MediaType+" does not match the file extension (expected "+expected+")"))
+		}
+	}
+	return issues
+}
+
+// baseMediaType returns the media type without parameters (e.g., "text/plain" for "text/plain; charset=utf-8")
+func baseMediaType(s string) string {
+	mt, _, err := mime.ParseMediaType(s)
+	if err != nil {
+		return s
+	}
+	return mt
+}
+
+func newLintCmd(opts *options) *cobra.Command {
+	checkOpts := &checkOptions{}
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "lint FILE",
+		Short: "Validate a bottle metadata file and check for good practices",
+		Long: `Validate a bottle metadata file (entry.yaml or JSON of any version, "-" for stdin) and warn about good practices
+that it does not follow (e.g., an outdated API version, unknown fields, a missing description or authors, invalid metric details, or an
+artifact media type that does not match its file extension).
+The command exits with code 1 if the bottle is invalid (or has warnings with --strict) and 2 if it could not be checked.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, report, err := checkOpts.check(cmd, args[0], val.SeverityWarning)
+			if err != nil {
+				return err
+			}
+			if doc != nil {
+				report.Add(lint(doc)...)
+			}
+			return writeResult(cmd, opts, checkResult{
+				File:       args[0],
+				APIVersion: apiVersion(doc),
+				Valid:      !report.HasErrors() && !(strict && len(report.Issues) > 0),
+				Issues:     report.Issues,
+			})
+		},
+	}
+	checkOpts.addFlags(cmd)
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail when there are warnings")
+	return cmd
+}
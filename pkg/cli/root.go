@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+
+	bottle "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io"
+)
+
+// Exit codes of the bottle-schema command
+const (
+	// ExitOK is the exit code when the command succeeds
+	ExitOK = 0
+
+	// ExitFailed is the exit code when the bottle fails the check (e.g., it is invalid)
+	ExitFailed = 1
+
+	// ExitError is the exit code when the command could not be run (e.g., bad arguments or an unreadable file)
+	ExitError = 2
+)
+
+// ErrCheckFailed is returned by the commands when the bottle fails the check
+var ErrCheckFailed = errors.New("bottle check failed")
+
+// ExitCode returns the exit code for the error returned by the command
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrCheckFailed):
+		return ExitFailed
+	default:
+		return ExitError
+	}
+}
+
+// Output formats
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// options are the flags shared by the commands
+type options struct {
+	output string
+}
+
+// NewRootCmd returns the bottle-schema command
+func NewRootCmd() *cobra.Command {
+	opts := &options{}
+	cmd := &cobra.Command{
+		Use:           "bottle-schema",
+		Short:         "Work with bottle metadata files",
+		Long:          "Validate, lint, convert and identify bottle metadata files (entry.yaml or JSON) of any version.",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch opts.output {
+			case outputText, outputJSON:
+				return nil
+			default:
+				return fmt.Errorf("unsupported output format %q (use %q or %q)", opts.output, outputText, outputJSON)
+			}
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", outputText, "Output format (text or json)")
+
+	cmd.AddCommand(
+		newValidateCmd(opts),
+		newLintCmd(opts),
+		newConvertCmd(),
+		newInitCmd(),
+		newIDCmd(opts),
+	)
+	return cmd
+}
+
+// readFile reads the file (or stdin for "-")
+func readFile(cmd *cobra.Command, name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(name)
+}
+
+// readManifest reads the manifest from the JSON file (an empty name returns nil)
+func readManifest(cmd *cobra.Command, name string) (*ocispecv1.Manifest, error) {
+	if name == "" {
+		return nil, nil
+	}
+	data, err := readFile(cmd, name)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	manifest := &ocispecv1.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest %s: %w", name, err)
+	}
+	return manifest, nil
+}
+
+// readDocument reads the bottle metadata file (of any version, JSON or YAML) and converts it to v1
+func readDocument(cmd *cobra.Command, name string, manifest *ocispecv1.Manifest) (*bottle.LoadResult, error) {
+	data, err := readFile(cmd, name)
+	if err != nil {
+		return nil, err
+	}
+	return loadDocument(name, data, manifest)
+}
+
+// loadDocument decodes the bottle metadata file (of any version, JSON or YAML) and converts it to v1
+func loadDocument(name string, data []byte, manifest *ocispecv1.Manifest) (*bottle.LoadResult, error) {
+	doc, err := bottle.Load(bytes.NewReader(data), bottle.LoadOptions{Manifest: manifest})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return doc, nil
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
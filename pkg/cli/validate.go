@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	bottle "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// checkResult is the JSON output of the validate and lint commands
+type checkResult struct {
+	// File that was checked ("-" for stdin)
+	File string `json:"file"`
+
+	// APIVersion of the file (before it was converted to v1)
+	APIVersion string `json:"apiVersion"`
+
+	// Valid is false if the bottle failed the check
+	Valid bool `json:"valid"`
+
+	// Issues found in the bottle
+	Issues val.IssueList `json:"issues"`
+}
+
+// writeResult writes the result in the output format and returns ErrCheckFailed if the bottle failed the check
+func writeResult(cmd *cobra.Command, opts *options, result checkResult) error {
+	if result.Issues == nil {
+		result.Issues = val.IssueList{}
+	}
+	out := cmd.OutOrStdout()
+	if opts.output == outputJSON {
+		if err := writeJSON(out, result); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range result.Issues {
+			if _, err := fmt.Fprintf(out, "%s: %s: %s [%s]\n", result.File, issue.Severity, issue.Error(), issue.Code); err != nil {
+				return err
+			}
+		}
+		status := "valid"
+		if !result.Valid {
+			status = "invalid"
+		}
+		if _, err := fmt.Fprintf(out, "%s: %s (%s, %d issues)\n", result.File, status, result.APIVersion, len(result.Issues)); err != nil {
+			return err
+		}
+	}
+	if !result.Valid {
+		return ErrCheckFailed
+	}
+	return nil
+}
+
+// DecodeCodePrefix prefixes the type of strict decoding problems (see bottle.DecodeErrorType) to form the issue codes
+// (e.g., "decode_unknown_field")
+const DecodeCodePrefix = "decode_"
+
+// checkOptions are the flags of the validate and lint commands
+type checkOptions struct {
+	manifest string
+	layers   bool
+}
+
+func (o *checkOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.manifest, "manifest", "m", "", "OCI manifest (JSON) of the bottle to check the bottle against")
+	cmd.Flags().BoolVar(&o.layers, "layers", false, "Check the digests and sizes of the layers and config in the manifest against the bottle (requires --manifest)")
+}
+
+// check reads and validates the file.
+// If decodeSeverity is not empty the problems found by strict decoding are reported with that severity.  The document
+// is nil if it could not be decoded but the strict decoding problems explain why (the report then has errors).
+func (o *checkOptions) check(cmd *cobra.Command, name string, decodeSeverity val.Severity) (*bottle.LoadResult, *val.Report, error) {
+	if o.layers && o.manifest == "" {
+		return nil, nil, fmt.Errorf("--layers requires --manifest")
+	}
+	manifest, err := readManifest(cmd, o.manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := readFile(cmd, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &val.Report{}
+	if decodeSeverity != "" {
+		issues, err := decodeIssues(bottle.CheckStrict(data), decodeSeverity)
+		if err != nil {
+			return nil, nil, err
+		}
+		report.Add(issues...)
+	}
+	doc, err := loadDocument(name, data, manifest)
+	if err != nil {
+		if len(report.Issues) == 0 {
+			return nil, nil, err
+		}
+		report.Add(val.NewIssue(nil, val.CodeInvalid, err.Error()))
+		return nil, report, nil
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if manifest != nil {
+		ctx = val.ContextWithManifest(ctx, manifest)
+	}
+	if o.layers {
+		ctx = val.ContextWithLayerConsistency(ctx)
+	}
+	report.Add(doc.Bottle.ValidationReport(ctx).Issues...)
+	return doc, report, nil
+}
+
+// decodeIssues converts the problems found by strict decoding (bottle.DecodeErrors) to issues with the severity
+func decodeIssues(err error, severity val.Severity) (val.IssueList, error) {
+	if err == nil {
+		return nil, nil
+	}
+	var errs bottle.DecodeErrors
+	if !errors.As(err, &errs) {
+		return nil, err
+	}
+	issues := make(val.IssueList, len(errs))
+	for i, e := range errs {
+		located := *e
+		located.Field = ""
+		issues[i] = val.Issue{
+			Field:    e.Field,
+			Code:     DecodeCodePrefix + string(e.Type),
+			Severity: severity,
+			Message:  located.Error(),
+		}
+	}
+	return issues, nil
+}
+
+// apiVersion returns the API version of the document (empty if it could not be decoded)
+func apiVersion(doc *bottle.LoadResult) string {
+	if doc == nil {
+		return ""
+	}
+	return doc.OriginalVersion.String()
+}
+
+func newValidateCmd(opts *options) *cobra.Command {
+	checkOpts := &checkOptions{}
+	var strict bool
+	cmd := &cobra.Command{
+		Use:   "validate FILE",
+		Short: "Validate a bottle metadata file",
+		Long: `Validate a bottle metadata file (entry.yaml or JSON of any version, "-" for stdin).
+The bottle is converted to the latest version before it is validated.
+With --strict, unknown fields (e.g., misspelled field names), values of the wrong type and duplicate keys are errors
+that are located by line and column.
+The command exits with code 1 if the bottle is invalid and 2 if it could not be checked.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var decodeSeverity val.Severity
+			if strict {
+				decodeSeverity = val.SeverityError
+			}
+			doc, report, err := checkOpts.check(cmd, args[0], decodeSeverity)
+			if err != nil {
+				return err
+			}
+			return writeResult(cmd, opts, checkResult{
+				File:       args[0],
+				APIVersion: apiVersion(doc),
+				Valid:      !report.HasErrors(),
+				Issues:     report.Issues,
+			})
+		},
+	}
+	checkOpts.addFlags(cmd)
+	cmd.Flags().BoolVar(&strict, "strict", false, "Reject unknown fields, values of the wrong type and duplicate keys")
+	return cmd
+}
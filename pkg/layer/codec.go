@@ -0,0 +1,234 @@
+package layer
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/act3-ai/bottle-schema/pkg/archive"
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+)
+
+// Compression is the compression algorithm applied to a layer
+type Compression string
+
+const (
+	// CompressionNone indicates the layer is not compressed
+	CompressionNone Compression = ""
+
+	// CompressionGzip indicates the layer is compressed with gzip
+	CompressionGzip Compression = "gzip"
+
+	// CompressionZstd indicates the layer is compressed with zstd
+	CompressionZstd Compression = "zstd"
+)
+
+// ErrUnsupportedMediaType is returned when no codec is registered for a media type
+var ErrUnsupportedMediaType = errors.New("unsupported layer media type")
+
+// ErrNotForNewBottles is returned when encoding with a codec for a media type that should not be used to create new
+// bottles (i.e., the "Old" and "Legacy" media types)
+var ErrNotForNewBottles = errors.New("media type should not be used to create new bottles")
+
+// Codec describes how the layers of a media type are encoded
+type Codec struct {
+	// MediaType of the layer
+	MediaType string
+
+	// Compression applied to the layer (after archiving)
+	Compression Compression
+
+	// Archived is true if the layer is a tar archive of a directory part (false for file parts)
+	Archived bool
+
+	// PartNameInArchive is true if the paths in the archive start with the part name (the "Old" and "Legacy" formats).
+	// Otherwise the paths are relative to the part's directory.
+	PartNameInArchive bool
+
+	// Deprecated is true if the media type should not be used to create new bottles
+	Deprecated bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Codec{}
+)
+
+func init() {
+	for _, c := range []Codec{
+		{MediaType: mediatype.MediaTypeLayerTarZstd, Compression: CompressionZstd, Archived: true},
+		{MediaType: mediatype.MediaTypeLayerTarGzip, Compression: CompressionGzip, Archived: true},
+		{MediaType: mediatype.MediaTypeLayerTar, Archived: true},
+		{MediaType: mediatype.MediaTypeLayerZstd, Compression: CompressionZstd},
+		{MediaType: mediatype.MediaTypeLayer},
+
+		{MediaType: mediatype.MediaTypeLayerTarZstdOld, Compression: CompressionZstd, Archived: true, PartNameInArchive: true, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerTarGzipOld, Compression: CompressionGzip, Archived: true, PartNameInArchive: true, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerTarOld, Archived: true, PartNameInArchive: true, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerRawOld, Deprecated: true},
+
+		{MediaType: mediatype.MediaTypeLayerTarZstdLegacy, Compression: CompressionZstd, Archived: true, PartNameInArchive: true, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerTarGzipLegacy, Compression: CompressionGzip, Archived: true, PartNameInArchive: true, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerTarLegacy, Archived: true, PartNameInArchive: true, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerZstdLegacy, Compression: CompressionZstd, Deprecated: true},
+		{MediaType: mediatype.MediaTypeLayerRawLegacy, Deprecated: true},
+	} {
+		Register(c)
+	}
+}
+
+// Register adds (or replaces) the codec for c.MediaType
+func Register(c Codec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[c.MediaType] = c
+}
+
+// Lookup returns the codec for the layer media type
+func Lookup(mediaType string) (Codec, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registry[mediaType]
+	if !ok {
+		return Codec{}, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, mediaType)
+	}
+	return c, nil
+}
+
+// MediaTypes returns the (sorted) media types with a registered codec
+func MediaTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	mediaTypes := make([]string, 0, len(registry))
+	for mt := range registry {
+		mediaTypes = append(mediaTypes, mt)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// Compress returns a writer that compresses to w.  The writer must be closed to flush the compressed data (w is
+// not closed).
+func (c Codec) Compress(w io.Writer) (io.WriteCloser, error) {
+	switch c.Compression {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", c.Compression)
+	}
+}
+
+// Decompress returns a reader of the uncompressed data from r.  The reader must be closed (r is not closed).
+func (c Codec) Decompress(r io.Reader) (io.ReadCloser, error) {
+	switch c.Compression {
+	case CompressionNone:
+		return io.NopCloser(r), nil
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", c.Compression)
+	}
+}
+
+// Archive writes the uncompressed content of the part (the archive for directory parts, otherwise the file) in fsys
+// to w.  This is the content that the part's size and digest are computed from.
+func (c Codec) Archive(fsys fs.FS, partName string, w io.Writer) error {
+	name := path.Clean(strings.TrimSuffix(partName, "/"))
+	if !c.Archived {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}
+	if c.PartNameInArchive {
+		return fmt.Errorf("%w: %s", ErrNotForNewBottles, c.MediaType)
+	}
+	return archive.Tar(fsys, name, w)
+}
+
+// Extract writes the uncompressed content in r (as produced by Archive) to the part in the directory dir.
+// Archives with links (which Archive never writes) are rejected.
+func (c Codec) Extract(r io.Reader, dir, partName string) error {
+	name := path.Clean(strings.TrimSuffix(partName, "/"))
+	if !c.Archived {
+		return writeFile(filepath.Join(dir, filepath.FromSlash(name)), r, 0o644)
+	}
+	stripPrefix := ""
+	if c.PartNameInArchive {
+		stripPrefix = name
+	}
+	return extractTar(r, filepath.Join(dir, filepath.FromSlash(name)), stripPrefix)
+}
+
+// Encode writes the layer for the part in fsys to w (archiving and compressing as needed)
+func (c Codec) Encode(fsys fs.FS, partName string, w io.Writer) error {
+	if c.Deprecated {
+		return fmt.Errorf("%w: %s", ErrNotForNewBottles, c.MediaType)
+	}
+	cw, err := c.Compress(w)
+	if err != nil {
+		return err
+	}
+	if err := c.Archive(fsys, partName, cw); err != nil {
+		cw.Close()
+		return err
+	}
+	return cw.Close()
+}
+
+// Decode writes the part from the layer in r to the directory dir (decompressing and extracting as needed)
+func (c Codec) Decode(r io.Reader, dir, partName string) error {
+	rc, err := c.Decompress(r)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return c.Extract(rc, dir, partName)
+}
+
+// writeFile writes the content of r to the file p (creating the parent directories)
+func writeFile(p string, r io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", p, err)
+	}
+	return f.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
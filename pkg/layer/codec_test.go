@@ -0,0 +1,161 @@
+package layer
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+)
+
+func TestLookup(t *testing.T) {
+	assert := assert.New(t)
+
+	// every layer media type has a codec that agrees with the mediatype package
+	assert.Len(MediaTypes(), 14)
+	for _, mt := range MediaTypes() {
+		c, err := Lookup(mt)
+		assert.NoError(err)
+		assert.True(mediatype.IsLayer(mt), mt)
+		assert.Equal(mediatype.IsArchived(mt), c.Archived, mt)
+		assert.Equal(mediatype.IsCompressed(mt), c.Compression != CompressionNone, mt)
+		assert.Equal(mediatype.IsRaw(mt), !c.Archived && c.Compression == CompressionNone, mt)
+	}
+
+	_, err := Lookup("application/octet-stream")
+	assert.ErrorIs(err, ErrUnsupportedMediaType)
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/a.txt":     &fstest.MapFile{Data: []byte("a")},
+		"data/sub/b.txt": &fstest.MapFile{Data: []byte("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")},
+		"file.txt":       &fstest.MapFile{Data: []byte("file")},
+	}
+	tests := []struct {
+		mediaType string
+		part      string
+	}{
+		{mediatype.MediaTypeLayerTarZstd, "data/"},
+		{mediatype.MediaTypeLayerTarGzip, "data/"},
+		{mediatype.MediaTypeLayerTar, "data/"},
+		{mediatype.MediaTypeLayerZstd, "file.txt"},
+		{mediatype.MediaTypeLayer, "file.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			c, err := Lookup(tt.mediaType)
+			require.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, c.Encode(fsys, tt.part, buf))
+
+			dir := t.TempDir()
+			require.NoError(t, c.Decode(buf, dir, tt.part))
+			for name, f := range fsys {
+				if !c.Archived && name != tt.part || c.Archived && filepath.Dir(name) == "." {
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				assert.NoError(t, err)
+				assert.Equal(t, f.Data, data)
+			}
+
+			// a single file
+			name := tt.part
+			if c.Archived {
+				name = "data/sub/b.txt"
+			}
+			buf.Reset()
+			require.NoError(t, c.Encode(fsys, tt.part, buf))
+			out := &bytes.Buffer{}
+			assert.NoError(t, c.ExtractFile(buf, tt.part, name, out))
+			assert.Equal(t, fsys[name].Data, out.Bytes())
+
+			buf.Reset()
+			require.NoError(t, c.Encode(fsys, tt.part, buf))
+			assert.ErrorIs(t, c.ExtractFile(buf, tt.part, "data/missing.txt", out), ErrFileNotFound)
+		})
+	}
+}
+
+func TestPartPath(t *testing.T) {
+	tests := []struct {
+		part, path string
+		want       string
+		wantOk     bool
+	}{
+		{"file.txt", "file.txt", ".", true},
+		{"data/", "data/sub/b.txt", "sub/b.txt", true},
+		{"data", "data/a.txt", "a.txt", true},
+		{"data/", "data", ".", true},
+		{"data/", "database.txt", "", false},
+		{"file.txt", "other.txt", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := PartPath(tt.part, tt.path)
+		assert.Equal(t, tt.wantOk, ok, tt.path)
+		assert.Equal(t, tt.want, got, tt.path)
+	}
+}
+
+func TestCodec_Old(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Lookup(mediatype.MediaTypeLayerTarOld)
+	assert.NoError(err)
+	assert.ErrorIs(c.Encode(fstest.MapFS{"data/a": &fstest.MapFile{}}, "data", &bytes.Buffer{}), ErrNotForNewBottles)
+
+	// the part name is in the old archives
+	archive := func(names ...string) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		for _, name := range names {
+			assert.NoError(tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Size: 1, Mode: 0o644}))
+			_, err := tw.Write([]byte("x"))
+			assert.NoError(err)
+		}
+		assert.NoError(tw.Close())
+		return buf
+	}
+
+	dir := t.TempDir()
+	assert.NoError(c.Extract(archive("data/a.txt", "data/sub/b.txt"), dir, "data/"))
+	assert.FileExists(filepath.Join(dir, "data", "sub", "b.txt"))
+
+	assert.ErrorContains(c.Extract(archive("other/a.txt"), dir, "data"), "not in part")
+
+	out := &bytes.Buffer{}
+	assert.NoError(c.ExtractFile(archive("data/a.txt", "data/sub/b.txt"), "data/", "data/sub/b.txt", out))
+	assert.Equal("x", out.String())
+
+	c, err = Lookup(mediatype.MediaTypeLayerTar)
+	assert.NoError(err)
+	assert.ErrorContains(c.Extract(archive("../escape.txt"), dir, "data"), "outside of the part")
+}
+
+func TestCodec_ExtractLinks(t *testing.T) {
+	c, err := Lookup(mediatype.MediaTypeLayerTar)
+	require.NoError(t, err)
+
+	// each link stays within the part on its own but together they escape it
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a/b", Typeflag: tar.TypeSymlink, Linkname: ".."}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "a/b/evil.txt", Typeflag: tar.TypeReg, Size: 1, Mode: 0o644}))
+	_, err = tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+	assert.ErrorContains(t, c.Extract(buf, dir, "data/"), "is a link")
+	assert.NoFileExists(t, filepath.Join(dir, "evil.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "data", "evil.txt"))
+}
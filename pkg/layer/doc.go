@@ -0,0 +1,4 @@
+// Package layer encodes bottle parts into layers and decodes layers back into files.
+// Each layer media type (including the "Old" and "Legacy" variants) is registered with a Codec that knows how the
+// layer is compressed and archived so consumers do not need to handle each media type themselves.
+package layer
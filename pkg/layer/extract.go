@@ -0,0 +1,153 @@
+package layer
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// extractTar extracts the tar archive in r into the directory dest.
+// If stripPrefix is not empty every path in the archive must be within stripPrefix which is removed.
+// Paths that would be written outside of dest and links are an error.
+func extractTar(r io.Reader, dest, stripPrefix string) error {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+
+		rel, err := archivePath(hdr.Name, stripPrefix)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			// archive.Tar never writes links and extracting them safely would require resolving every path against
+			// the links already extracted (a link to "." followed by a link to ".." escapes the part)
+			return fmt.Errorf("archive entry %q is a link (%s) which is not supported", hdr.Name, hdr.Linkname)
+		default:
+			return fmt.Errorf("archive entry %q has an unsupported type %q", hdr.Name, hdr.Typeflag)
+		}
+	}
+}
+
+// archivePath returns the path of the archive entry name relative to the part
+func archivePath(name, stripPrefix string) (string, error) {
+	rel := path.Clean(name)
+	if stripPrefix != "" {
+		switch {
+		case rel == stripPrefix:
+			rel = "."
+		case strings.HasPrefix(rel, stripPrefix+"/"):
+			rel = rel[len(stripPrefix)+1:]
+		default:
+			return "", fmt.Errorf("archive entry %q is not in part %q", name, stripPrefix)
+		}
+	}
+	if path.IsAbs(rel) || !isLocal(rel) {
+		return "", fmt.Errorf("archive entry %q is outside of the part", name)
+	}
+	return rel, nil
+}
+
+// isLocal returns true if the (cleaned) relative path p does not escape its parent
+func isLocal(p string) bool {
+	return p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// ErrFileNotFound is returned by ExtractFile when the file is not in the layer
+var ErrFileNotFound = errors.New("file not found in layer")
+
+// PartPath returns the path of the file p (relative to the bottle) relative to the part partName.
+// The path of a file part is ".".  It returns false if p is not in the part.
+func PartPath(partName, p string) (string, bool) {
+	name := path.Clean(strings.TrimSuffix(partName, "/"))
+	p = path.Clean(p)
+	switch {
+	case p == name:
+		return ".", true
+	case strings.HasPrefix(p, name+"/"):
+		return p[len(name)+1:], true
+	default:
+		return "", false
+	}
+}
+
+// EntryPath returns the path of the archive entry name (in a layer of the part partName) relative to the part.
+// Paths outside of the part are an error.
+func (c Codec) EntryPath(name, partName string) (string, error) {
+	stripPrefix := ""
+	if c.PartNameInArchive {
+		stripPrefix = path.Clean(strings.TrimSuffix(partName, "/"))
+	}
+	return archivePath(name, stripPrefix)
+}
+
+// ExtractFile writes the content of the file p (relative to the bottle) from the layer in r of the part partName to w.
+// Only that file is written, the rest of the layer is skipped.  The error wraps ErrFileNotFound if p is not a file
+// in the layer.
+func (c Codec) ExtractFile(r io.Reader, partName, p string, w io.Writer) error {
+	rel, ok := PartPath(partName, p)
+	if !ok {
+		return fmt.Errorf("%w: %q is not in part %q", ErrFileNotFound, p, partName)
+	}
+	rc, err := c.Decompress(r)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if !c.Archived {
+		if rel != "." {
+			return fmt.Errorf("%w: %q is not in file part %q", ErrFileNotFound, p, partName)
+		}
+		_, err := io.Copy(w, rc)
+		return err
+	}
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: %q", ErrFileNotFound, p)
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		entry, err := c.EntryPath(hdr.Name, partName)
+		if err != nil {
+			return err
+		}
+		if entry != rel {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%w: %q is not a regular file", ErrFileNotFound, p)
+		}
+		_, err = io.Copy(w, tr)
+		return err
+	}
+}
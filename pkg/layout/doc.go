@@ -0,0 +1,4 @@
+// Package layout verifies bottles stored in an OCI image layout directory (index.json and blobs/).
+// It is the end-to-end integrity check for a bottle: from the manifest, through the bottle config, down to the
+// content of every part and public artifact.
+package layout
@@ -0,0 +1,329 @@
+package layout
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	bottle "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io"
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/layer"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// Result is the outcome of verifying a bottle in an OCI image layout
+type Result struct {
+	// Manifest of the bottle
+	Manifest *ocispecv1.Manifest
+
+	// Bottle is the bottle config converted to v1
+	Bottle *v1.Bottle
+
+	// Report lists every integrity problem found (use Report.Err() to determine if the bottle is intact)
+	Report *val.Report
+}
+
+var (
+	manifestPath = field.NewPath("manifest")
+	configPath   = manifestPath.Child("config")
+	layersPath   = manifestPath.Child("layers")
+)
+
+// VerifyLayout verifies the bottle with the manifest digest manifestDigest in the OCI image layout in dir.
+// If manifestDigest is empty the index must contain exactly one manifest.
+// The bottle config is decoded (from any version), converted to v1 and validated against the manifest.  Then every
+// layer is streamed, decompressed and unarchived to check the digest and size of each blob, part and public artifact.
+// An error is returned if the verification cannot be performed (e.g., the manifest cannot be read); problems with the
+// bottle itself are listed in the report.
+func VerifyLayout(dir string, manifestDigest digest.Digest) (*Result, error) {
+	if err := checkLayoutVersion(dir); err != nil {
+		return nil, err
+	}
+	if manifestDigest == "" {
+		var err error
+		if manifestDigest, err = onlyManifest(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := readBlob(dir, ocispecv1.Descriptor{Digest: manifestDigest, Size: -1})
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	manifest := &ocispecv1.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	result := &Result{
+		Manifest: manifest,
+		Report:   &val.Report{},
+	}
+	manifestErr := val.ValidateManifest(*manifest)
+	result.Report.AddError(manifestPath, manifestErr)
+
+	config, err := readBlob(dir, manifest.Config)
+	if err != nil {
+		result.Report.Add(blobIssue(configPath, err))
+		return result, nil
+	}
+	if result.Bottle, err = decodeBottle(config, nil); err != nil {
+		return nil, err
+	}
+	if manifestErr != nil {
+		// the bottle cannot be checked against an invalid manifest
+		result.Report.Add(result.Bottle.ValidationReport(context.Background()).Issues...)
+		return result, nil
+	}
+	if len(manifest.Layers) == len(result.Bottle.Parts) {
+		// the manifest is only used by the conversion when the layers match up with the parts
+		if result.Bottle, err = decodeBottle(config, manifest); err != nil {
+			return nil, err
+		}
+	}
+	report := result.Bottle.ValidationReport(val.ContextWithManifest(context.Background(), manifest))
+	result.Report.Add(report.Issues...)
+
+	if len(manifest.Layers) != len(result.Bottle.Parts) {
+		// already reported, the content cannot be matched up with the parts
+		return result, nil
+	}
+	for i, desc := range manifest.Layers {
+		if err := verifyLayer(dir, i, desc, result.Bottle, result.Report); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// checkLayoutVersion ensures dir is an OCI image layout
+func checkLayoutVersion(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, ocispecv1.ImageLayoutFile))
+	if err != nil {
+		return fmt.Errorf("not an OCI image layout: %w", err)
+	}
+	layout := ocispecv1.ImageLayout{}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return fmt.Errorf("decoding %s: %w", ocispecv1.ImageLayoutFile, err)
+	}
+	if layout.Version != ocispecv1.ImageLayoutVersion {
+		return fmt.Errorf("unsupported OCI image layout version %q", layout.Version)
+	}
+	return nil
+}
+
+// onlyManifest returns the digest of the only manifest in the index of the layout
+func onlyManifest(dir string) (digest.Digest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ocispecv1.ImageIndexFile))
+	if err != nil {
+		return "", err
+	}
+	index := ocispecv1.Index{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return "", fmt.Errorf("decoding %s: %w", ocispecv1.ImageIndexFile, err)
+	}
+	if len(index.Manifests) != 1 {
+		return "", fmt.Errorf("the manifest digest is required since the index has %d manifests", len(index.Manifests))
+	}
+	return index.Manifests[0].Digest, nil
+}
+
+// blobPath returns the path to the blob in the layout
+func blobPath(dir string, dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ocispecv1.ImageBlobsDir, dgst.Algorithm().String(), dgst.Encoded()), nil
+}
+
+// errBlobMismatch is returned when the content of a blob does not match its descriptor
+var errBlobMismatch = errors.New("blob does not match its descriptor")
+
+// readBlob reads the (small) blob and verifies it against desc.  A negative size is not checked.
+func readBlob(dir string, desc ocispecv1.Descriptor) ([]byte, error) {
+	p, err := blobPath(dir, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if desc.Digest.Algorithm().FromBytes(data) != desc.Digest {
+		return nil, fmt.Errorf("%w: the digest is not %s", errBlobMismatch, desc.Digest)
+	}
+	if desc.Size >= 0 && int64(len(data)) != desc.Size {
+		return nil, fmt.Errorf("%w: the size is %d not %d", errBlobMismatch, len(data), desc.Size)
+	}
+	return data, nil
+}
+
+// blobIssue returns the issue for the error from reading a blob
+func blobIssue(fldPath *field.Path, err error) val.Issue {
+	if errors.Is(err, errBlobMismatch) {
+		return val.NewIssue(fldPath, val.CodeBlobMismatch, err.Error())
+	}
+	return val.NewIssue(fldPath, val.CodeBlobMissing, err.Error())
+}
+
+// decodeBottle decodes the bottle config (of any version) and converts it to v1
+func decodeBottle(config []byte, manifest *ocispecv1.Manifest) (*v1.Bottle, error) {
+	result, err := bottle.Load(bytes.NewReader(config), bottle.LoadOptions{Manifest: manifest})
+	if err != nil {
+		return nil, fmt.Errorf("loading bottle config: %w", err)
+	}
+	return result.Bottle, nil
+}
+
+// contentCheck computes the digest and size of a stream to compare with the expected values
+type contentCheck struct {
+	digester digest.Digester
+	size     int64
+}
+
+// newContentCheck returns a contentCheck using the algorithm of dgst (or the canonical algorithm)
+func newContentCheck(dgst digest.Digest) *contentCheck {
+	alg := dgst.Algorithm()
+	if !alg.Available() {
+		alg = digest.Canonical
+	}
+	return &contentCheck{digester: alg.Digester()}
+}
+
+func (c *contentCheck) Write(p []byte) (int, error) {
+	n, err := c.digester.Hash().Write(p)
+	c.size += int64(n)
+	return n, err
+}
+
+// verifyLayer streams the layer of part i and adds any problems to the report
+func verifyLayer(dir string, i int, desc ocispecv1.Descriptor, b *v1.Bottle, report *val.Report) error {
+	part := b.Parts[i]
+	layerPath := layersPath.Index(i)
+	partPath := field.NewPath("parts").Index(i)
+
+	codec, err := layer.Lookup(desc.MediaType)
+	if err != nil {
+		// already reported by the manifest validation
+		return nil //nolint:nilerr
+	}
+	p, err := blobPath(dir, desc.Digest)
+	if err != nil {
+		return nil //nolint:nilerr
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		report.Add(val.NewIssue(layerPath, val.CodeBlobMissing, err.Error()))
+		return nil
+	}
+	defer f.Close()
+
+	// the public artifacts in this part (by the path relative to the part)
+	artifacts := map[string]int{}
+	for j, a := range b.PublicArtifacts {
+		if rel, ok := layer.PartPath(part.Name, a.Path); ok {
+			artifacts[rel] = j
+		}
+	}
+	found := map[int]*contentCheck{}
+
+	layerCheck := newContentCheck(desc.Digest)
+	lr := io.TeeReader(f, layerCheck)
+	partCheck := newContentCheck(part.Digest)
+	contentErr := func() error {
+		rc, err := codec.Decompress(lr)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		cr := io.TeeReader(rc, partCheck)
+
+		if !codec.Archived {
+			var w io.Writer = io.Discard
+			if j, ok := artifacts["."]; ok {
+				found[j] = newContentCheck(b.PublicArtifacts[j].Digest)
+				w = found[j]
+			}
+			_, err := io.Copy(w, cr)
+			return err
+		}
+
+		tr := tar.NewReader(cr)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			entry, err := codec.EntryPath(hdr.Name, part.Name)
+			if err != nil {
+				return err
+			}
+			j, ok := artifacts[entry]
+			if !ok || hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			found[j] = newContentCheck(b.PublicArtifacts[j].Digest)
+			if _, err := io.Copy(found[j], tr); err != nil {
+				return err
+			}
+		}
+		// the content includes the padding at the end of the archive
+		_, err = io.Copy(io.Discard, cr)
+		return err
+	}()
+	// the layer digest is of the whole blob (e.g., including anything after the compressed stream)
+	if _, err := io.Copy(io.Discard, lr); err != nil {
+		return fmt.Errorf("reading layer %d: %w", i, err)
+	}
+
+	if layerCheck.digester.Digest() != desc.Digest || layerCheck.size != desc.Size {
+		report.Add(val.NewIssue(layerPath, val.CodeBlobMismatch,
+			fmt.Sprintf("layer blob has digest %s and size %d but the descriptor has digest %s and size %d",
+				layerCheck.digester.Digest(), layerCheck.size, desc.Digest, desc.Size)))
+	}
+	if contentErr != nil {
+		report.Add(val.NewIssue(partPath, val.CodeContentDigest,
+			fmt.Sprintf("part '%s' (index %d) could not be decoded from its %s layer: %v", part.Name, i, desc.MediaType, contentErr)))
+		return nil
+	}
+	if dgst := partCheck.digester.Digest(); dgst != part.Digest {
+		report.Add(val.NewIssue(partPath.Child("digest"), val.CodeContentDigest,
+			fmt.Sprintf("part '%s' (index %d) has digest %s but its content has digest %s", part.Name, i, part.Digest, dgst)))
+	}
+	if partCheck.size != part.Size {
+		report.Add(val.NewIssue(partPath.Child("size"), val.CodeContentSize,
+			fmt.Sprintf("part '%s' (index %d) has size %d but its content has size %d", part.Name, i, part.Size, partCheck.size)))
+	}
+
+	for _, rel := range slices.Sorted(maps.Keys(artifacts)) {
+		j := artifacts[rel]
+		a := b.PublicArtifacts[j]
+		artifactPath := field.NewPath("publicArtifacts").Index(j)
+		check, ok := found[j]
+		if !ok {
+			report.Add(val.NewIssue(artifactPath.Child("path"), val.CodeArtifactMissing,
+				fmt.Sprintf("public artifact '%s' is not a file in part '%s'", a.Path, part.Name)))
+			continue
+		}
+		if dgst := check.digester.Digest(); dgst != a.Digest {
+			report.Add(val.NewIssue(artifactPath.Child("digest"), val.CodeArtifactDigest,
+				fmt.Sprintf("public artifact '%s' has digest %s but its content has digest %s", a.Path, a.Digest, dgst)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package layout
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/layer"
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// writeBlob writes data to the blobs of the layout in dir
+func writeBlob(t *testing.T, dir string, data []byte) digest.Digest {
+	t.Helper()
+	dgst := digest.FromBytes(data)
+	p := filepath.Join(dir, ocispecv1.ImageBlobsDir, dgst.Algorithm().String(), dgst.Encoded())
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	require.NoError(t, os.WriteFile(p, data, 0o644))
+	return dgst
+}
+
+// writeJSON writes v as JSON to the file name in dir
+func writeJSON(t *testing.T, dir, name string, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	return data
+}
+
+// testLayout writes a bottle with a (compressed) directory part and a file part to a new OCI image layout
+func testLayout(t *testing.T) (string, *ocispecv1.Manifest) {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"data/train.csv":  {Data: []byte("a,b\n1,2\n")},
+		"data/README.md":  {Data: []byte("# Data\n")},
+		"model.onnx":      {Data: []byte("not really a model")},
+		"data/sub/x.json": {Data: []byte("{}")},
+	}
+	bottle, err := v1.FromDirectory(fsys, v1.DirectoryOptions{})
+	require.NoError(t, err)
+	bottle.PublicArtifacts = []v1.PublicArtifact{
+		{Name: "Readme", Path: "data/README.md", MediaType: "text/markdown", Digest: digest.FromString("# Data\n")},
+		{Name: "Model", Path: "model.onnx", MediaType: "application/octet-stream", Digest: digest.FromString("not really a model")},
+	}
+
+	dir := t.TempDir()
+	var layers []v1.LayerInfo
+	for _, part := range bottle.Parts {
+		mediaType := mediatype.MediaTypeLayer
+		if part.Name == "data/" {
+			mediaType = mediatype.MediaTypeLayerTarZstd
+		}
+		codec, err := layer.Lookup(mediaType)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+		require.NoError(t, codec.Encode(fsys, part.Name, buf))
+		layers = append(layers, v1.LayerInfo{MediaType: mediaType, Digest: writeBlob(t, dir, buf.Bytes()), Size: int64(buf.Len())})
+	}
+
+	manifest, err := v1.BuildManifest(*bottle, layers, v1.ManifestOptions{ArtifactType: mediatype.MediaTypeBottle})
+	require.NoError(t, err)
+	config, err := bottle.CanonicalJSON()
+	require.NoError(t, err)
+	writeBlob(t, dir, config)
+
+	manifestData, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	writeJSON(t, dir, ocispecv1.ImageLayoutFile, ocispecv1.ImageLayout{Version: ocispecv1.ImageLayoutVersion})
+	writeJSON(t, dir, ocispecv1.ImageIndexFile, ocispecv1.Index{
+		Versioned: ocispec.Versioned{SchemaVersion: 2},
+		MediaType: ocispecv1.MediaTypeImageIndex,
+		Manifests: []ocispecv1.Descriptor{{
+			MediaType: ocispecv1.MediaTypeImageManifest,
+			Digest:    writeBlob(t, dir, manifestData),
+			Size:      int64(len(manifestData)),
+		}},
+	})
+	return dir, manifest
+}
+
+func TestVerifyLayout(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, manifest := testLayout(t)
+	result, err := VerifyLayout(dir, "")
+	assert.NoError(err)
+	assert.Equal(manifest, result.Manifest)
+	assert.Len(result.Bottle.Parts, 2)
+	assert.Empty(result.Report.Issues)
+
+	// unknown manifest
+	_, err = VerifyLayout(dir, digest.FromString("nope"))
+	assert.Error(err)
+
+	// not a layout
+	_, err = VerifyLayout(t.TempDir(), "")
+	assert.ErrorContains(err, "not an OCI image layout")
+}
+
+func TestVerifyLayout_Corrupt(t *testing.T) {
+	assert := assert.New(t)
+
+	codes := func(report *val.Report) map[string]string {
+		m := map[string]string{}
+		for _, issue := range report.Issues {
+			m[issue.Field] = issue.Code
+		}
+		return m
+	}
+
+	t.Run("layer", func(t *testing.T) {
+		dir, manifest := testLayout(t)
+		// replace the file part's content (same size)
+		desc := manifest.Layers[1]
+		p := filepath.Join(dir, ocispecv1.ImageBlobsDir, "sha256", desc.Digest.Encoded())
+		assert.NoError(os.WriteFile(p, []byte("not really a MODEL"), 0o644))
+
+		result, err := VerifyLayout(dir, "")
+		assert.NoError(err)
+		assert.Equal(map[string]string{
+			"manifest.layers[1]":        val.CodeBlobMismatch,
+			"parts[1].digest":           val.CodeContentDigest,
+			"publicArtifacts[1].digest": val.CodeArtifactDigest,
+		}, codes(result.Report))
+		assert.Error(result.Report.Err())
+	})
+
+	t.Run("missing layer", func(t *testing.T) {
+		dir, manifest := testLayout(t)
+		desc := manifest.Layers[0]
+		assert.NoError(os.Remove(filepath.Join(dir, ocispecv1.ImageBlobsDir, "sha256", desc.Digest.Encoded())))
+
+		result, err := VerifyLayout(dir, "")
+		assert.NoError(err)
+		assert.Equal(map[string]string{
+			"manifest.layers[0]": val.CodeBlobMissing,
+		}, codes(result.Report))
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		dir, manifest := testLayout(t)
+		assert.NoError(os.Remove(filepath.Join(dir, ocispecv1.ImageBlobsDir, "sha256", manifest.Config.Digest.Encoded())))
+
+		result, err := VerifyLayout(dir, "")
+		assert.NoError(err)
+		assert.Nil(result.Bottle)
+		assert.Equal(map[string]string{
+			"manifest.config": val.CodeBlobMissing,
+		}, codes(result.Report))
+	})
+}
+
+// writeLayout writes a new OCI image layout with the config and the manifest (with the config descriptor filled in)
+func writeLayout(t *testing.T, config []byte, manifest ocispecv1.Manifest) string {
+	t.Helper()
+	dir := t.TempDir()
+	manifest.Config = ocispecv1.Descriptor{
+		MediaType: mediatype.MediaTypeBottleConfig,
+		Digest:    writeBlob(t, dir, config),
+		Size:      int64(len(config)),
+	}
+	manifestData, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	writeJSON(t, dir, ocispecv1.ImageLayoutFile, ocispecv1.ImageLayout{Version: ocispecv1.ImageLayoutVersion})
+	writeJSON(t, dir, ocispecv1.ImageIndexFile, ocispecv1.Index{
+		Versioned: ocispec.Versioned{SchemaVersion: 2},
+		MediaType: ocispecv1.MediaTypeImageIndex,
+		Manifests: []ocispecv1.Descriptor{{
+			MediaType: ocispecv1.MediaTypeImageManifest,
+			Digest:    writeBlob(t, dir, manifestData),
+			Size:      int64(len(manifestData)),
+		}},
+	})
+	return dir
+}
+
+func TestVerifyLayout_InvalidManifest(t *testing.T) {
+	config := []byte(`{
+  "apiVersion": "data.act3-ace.io/v1beta1",
+  "kind": "Bottle",
+  "parts": [
+    {"name": "a.txt", "size": 1, "digest": "sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"},
+    {"name": "b.txt", "size": 1, "digest": "sha256:3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"}
+  ]
+}`)
+	layer := ocispecv1.Descriptor{
+		MediaType: mediatype.MediaTypeLayer,
+		Digest:    digest.FromString("a"),
+		Size:      1,
+	}
+	manifest := func(layers ...ocispecv1.Descriptor) ocispecv1.Manifest {
+		return ocispecv1.Manifest{
+			Versioned:    ocispec.Versioned{SchemaVersion: 2},
+			MediaType:    ocispecv1.MediaTypeImageManifest,
+			ArtifactType: mediatype.MediaTypeBottle,
+			Layers:       layers,
+		}
+	}
+
+	t.Run("fewer layers than parts", func(t *testing.T) {
+		result, err := VerifyLayout(writeLayout(t, config, manifest(layer)), "")
+		require.NoError(t, err)
+		assert.Len(t, result.Bottle.Parts, 2)
+		assert.Error(t, result.Report.Err())
+	})
+
+	t.Run("layer without a media type", func(t *testing.T) {
+		noMediaType := layer
+		noMediaType.MediaType = ""
+		result, err := VerifyLayout(writeLayout(t, config, manifest(layer, noMediaType)), "")
+		require.NoError(t, err)
+		assert.Len(t, result.Bottle.Parts, 2)
+		assert.Error(t, result.Report.Err())
+	})
+}
@@ -0,0 +1,200 @@
+package leaderboard
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/metric"
+)
+
+// DefaultAliases maps common alternative metric names (after Normalize) to their canonical names
+var DefaultAliases = map[string]string{
+	"acc":                    "accuracy",
+	"top1 accuracy":          "accuracy",
+	"top 1 accuracy":         "accuracy",
+	"accuracy top 1":         "accuracy",
+	"f1 score":               "f1",
+	"f1score":                "f1",
+	"auroc":                  "auc",
+	"roc auc":                "auc",
+	"mean average precision": "map",
+	"err":                    "error",
+	"error rate":             "error",
+	"ppl":                    "perplexity",
+	"mean squared error":     "mse",
+	"mean absolute error":    "mae",
+}
+
+// Entry is a bottle to compare
+type Entry struct {
+	// Name identifies the bottle in the results (e.g., its bottle ID or reference).  It must be unique.
+	Name string
+
+	// Bottle to compare
+	Bottle *v1.Bottle
+}
+
+// Options are the options for Compare
+type Options struct {
+	// Aliases maps alternative metric names to canonical names.  Both are normalized (see Normalize) and they are
+	// used in addition to (and take precedence over) DefaultAliases.
+	Aliases map[string]string
+}
+
+// Row is a metric aligned across the bottles
+type Row struct {
+	// Metric is the canonical (normalized) name of the metric
+	Metric string `json:"metric"`
+
+	// Unit that the values are converted to (the unit of the metric in the first bottle that has it)
+	Unit string `json:"unit,omitempty"`
+
+	// Direction in which the metric improves (empty if unknown)
+	Direction metric.Direction `json:"direction,omitempty"`
+
+	// Values has the metric of each bottle (in the order of Comparison.Bottles) converted to Unit.
+	// It is nil for the bottles that do not have the metric.
+	Values []*metric.Metric `json:"values"`
+
+	// Missing lists the bottles that do not have the metric
+	Missing []string `json:"missing,omitempty"`
+
+	// Incompatible is set when the bottles disagree on the unit dimension or direction of the metric so the values
+	// cannot be compared.  Incompatible values are left in their own unit.
+	Incompatible bool `json:"incompatible,omitempty"`
+}
+
+// Comparison is the metrics of several bottles aligned by metric name
+type Comparison struct {
+	// Bottles are the names of the bottles in the order they were given
+	Bottles []string `json:"bottles"`
+
+	// Rows are the metrics sorted by name
+	Rows []*Row `json:"rows"`
+
+	// Warnings are the problems that were worked around (e.g., a metric value that could not be parsed)
+	Warnings []string `json:"warnings,omitempty"`
+
+	// aliases used to align the metrics
+	aliases map[string]string
+}
+
+// Normalize returns the normalized form of a metric name used to align metrics across bottles.
+// The name is lower cased, a trailing unit (see metric.ParseUnit) in parentheses or brackets is removed and runs of spaces, underscores,
+// dashes and other punctuation become a single space (e.g., "Test_Accuracy (%)" becomes "test accuracy").
+func Normalize(name string) string {
+	name = strings.TrimSpace(name)
+	if n := len(name); n > 0 && (name[n-1] == ')' || name[n-1] == ']') {
+		// only a unit is removed (like metric.InferDetails) so "Accuracy (top-5)" keeps its qualifier
+		if open := strings.LastIndexAny(name, "(["); open > 0 {
+			if _, err := metric.ParseUnit(strings.TrimSpace(name[open+1 : n-1])); err == nil {
+				name = name[:open]
+			}
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// canonical returns the canonical name of the metric
+func canonical(name string, aliases map[string]string) string {
+	n := Normalize(name)
+	if c, ok := aliases[n]; ok {
+		return c
+	}
+	return n
+}
+
+// Compare aligns the metrics of the bottles by their canonical names
+func Compare(entries []Entry, opts Options) (*Comparison, error) {
+	aliases := maps.Clone(DefaultAliases)
+	for k, v := range opts.Aliases {
+		aliases[Normalize(k)] = Normalize(v)
+	}
+
+	c := &Comparison{Bottles: make([]string, len(entries)), aliases: aliases}
+	rows := map[string]*Row{}
+	for i, e := range entries {
+		if slices.Contains(c.Bottles[:i], e.Name) {
+			return nil, fmt.Errorf("bottle name %q is not unique", e.Name)
+		}
+		c.Bottles[i] = e.Name
+		if e.Bottle == nil {
+			return nil, fmt.Errorf("bottle %q is nil", e.Name)
+		}
+
+		metrics, err := metric.FromBottle(*e.Bottle)
+		if err != nil {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("bottle %s: %v", e.Name, err))
+		}
+		for _, m := range metrics {
+			name := canonical(m.Name, aliases)
+			row, ok := rows[name]
+			if !ok {
+				row = &Row{Metric: name, Unit: m.Unit, Values: make([]*metric.Metric, len(entries))}
+				rows[name] = row
+			}
+			if row.Values[i] != nil {
+				c.Warnings = append(c.Warnings, fmt.Sprintf("bottle %s: metric %q is a duplicate of %q (ignored)",
+					e.Name, m.Name, row.Values[i].Name))
+				continue
+			}
+			row.add(i, m)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(rows)) {
+		row := rows[name]
+		for i, v := range row.Values {
+			if v == nil {
+				row.Missing = append(row.Missing, c.Bottles[i])
+			}
+		}
+		c.Rows = append(c.Rows, row)
+	}
+	return c, nil
+}
+
+// add sets the value of bottle i, converted to the unit of the row
+func (r *Row) add(i int, m metric.Metric) {
+	if m.Direction != "" {
+		switch r.Direction {
+		case "":
+			r.Direction = m.Direction
+		case m.Direction:
+		default:
+			r.Incompatible = true
+		}
+	}
+	if !r.Incompatible {
+		converted, err := m.In(r.Unit)
+		if err == nil {
+			m = converted
+		} else {
+			r.Incompatible = true
+		}
+	}
+	r.Values[i] = &m
+}
+
+// Row returns the row of the metric (the name is normalized and the aliases are applied) or nil if there is none
+func (c *Comparison) Row(name string) *Row {
+	name = canonical(name, c.aliases)
+	for _, r := range c.Rows {
+		if r.Metric == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// index returns the index of the bottle or -1
+func (c *Comparison) index(bottle string) int {
+	return slices.Index(c.Bottles, bottle)
+}
@@ -0,0 +1,167 @@
+package leaderboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/metric"
+)
+
+func newBottle(metrics ...string) *v1.Bottle {
+	b := v1.NewBottle()
+	for i := 0; i+1 < len(metrics); i += 2 {
+		b.Metrics = append(b.Metrics, v1.Metric{Name: metrics[i], Value: metrics[i+1]})
+	}
+	return &b
+}
+
+// entries are three models with differently named metrics
+func entries() []Entry {
+	return []Entry{
+		{Name: "a", Bottle: newBottle("Accuracy", "0.91", "Training Loss", "0.30", "latency (ms)", "120")},
+		{Name: "b", Bottle: newBottle("acc", "0.93", "training_loss", "0.25", "latency (s)", "0.1")},
+		{Name: "c", Bottle: newBottle("top-1 accuracy", "0.93", "latency [ms]", "80", "epochs", "12")},
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "test accuracy", Normalize("Test_Accuracy (%)"))
+	assert.Equal(t, "f1 score", Normalize("  F1--Score "))
+	assert.Equal(t, "map 50 95", Normalize("mAP@.50:.95"))
+	assert.Equal(t, "latency", Normalize("latency [ms]"))
+	assert.Equal(t, "accuracy top 5", Normalize("Accuracy (top-5)"))
+	assert.NotEqual(t, Normalize("Accuracy (top-1)"), Normalize("Accuracy (top-5)"))
+}
+
+func TestCompare(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Compare(entries(), Options{})
+	require.NoError(t, err)
+	assert.Equal([]string{"a", "b", "c"}, c.Bottles)
+	assert.Empty(c.Warnings)
+
+	names := make([]string, len(c.Rows))
+	for i, r := range c.Rows {
+		names[i] = r.Metric
+	}
+	assert.Equal([]string{"accuracy", "epochs", "latency", "training loss"}, names)
+
+	accuracy := c.Row("ACC")
+	require.NotNil(t, accuracy)
+	assert.Equal(metric.HigherIsBetter, accuracy.Direction)
+	assert.Empty(accuracy.Missing)
+	assert.Equal("top-1 accuracy", accuracy.Values[2].Name)
+
+	latency := c.Row("latency")
+	assert.Equal("ms", latency.Unit)
+	assert.InDelta(100, latency.Values[1].Value, 1e-9, "converted to the unit of the first bottle")
+	assert.Equal("ms", latency.Values[1].Unit)
+
+	assert.Equal([]string{"c"}, c.Row("training loss").Missing)
+	assert.Equal([]string{"a", "b"}, c.Row("epochs").Missing)
+	assert.Empty(c.Row("epochs").Direction)
+	assert.Nil(c.Row("f1"))
+
+	// custom aliases
+	c, err = Compare(entries(), Options{Aliases: map[string]string{"Epochs": "Training Loss"}})
+	require.NoError(t, err)
+	assert.Empty(c.Row("training loss").Missing)
+
+	_, err = Compare([]Entry{{Name: "a", Bottle: newBottle()}, {Name: "a", Bottle: newBottle()}}, Options{})
+	assert.ErrorContains(err, `bottle name "a" is not unique`)
+	_, err = Compare([]Entry{{Name: "a"}}, Options{})
+	assert.ErrorContains(err, `bottle "a" is nil`)
+}
+
+func TestCompare_Problems(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Compare([]Entry{
+		{Name: "a", Bottle: newBottle("accuracy", "0.9", "acc", "0.8", "size", "1")},
+		{Name: "b", Bottle: newBottle("accuracy", "high", "size (ms)", "2")},
+	}, Options{})
+	require.NoError(t, err)
+	assert.Len(c.Warnings, 2)
+	assert.Contains(c.Warnings[0], `metric "acc" is a duplicate of "accuracy"`)
+	assert.Contains(c.Warnings[1], `bottle b: metric "accuracy": invalid value "high"`)
+	assert.Equal([]string{"b"}, c.Row("accuracy").Missing)
+	assert.InDelta(0.9, c.Row("accuracy").Values[0].Value, 1e-9)
+
+	size := c.Row("size")
+	assert.True(size.Incompatible)
+	_, err = c.Leaderboard("size")
+	assert.ErrorContains(err, "not comparable")
+}
+
+func TestLeaderboard(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Compare(entries(), Options{})
+	require.NoError(t, err)
+
+	lb, err := c.Leaderboard("accuracy")
+	require.NoError(t, err)
+	assert.Equal([]Standing{
+		{Rank: 1, Bottle: "b", Value: 0.93},
+		{Rank: 1, Bottle: "c", Value: 0.93},
+		{Rank: 3, Bottle: "a", Value: 0.91},
+	}, lb.Standings)
+
+	lb, err = c.Leaderboard("latency")
+	require.NoError(t, err)
+	assert.Equal(metric.LowerIsBetter, lb.Direction)
+	assert.Equal([]string{"c", "b", "a"}, []string{lb.Standings[0].Bottle, lb.Standings[1].Bottle, lb.Standings[2].Bottle})
+
+	lb, err = c.Leaderboard("training loss")
+	require.NoError(t, err)
+	assert.Len(lb.Standings, 2)
+	assert.Equal([]string{"c"}, lb.Missing)
+
+	_, err = c.Leaderboard("epochs")
+	assert.ErrorContains(err, "direction")
+	_, err = c.Leaderboard("f1")
+	assert.ErrorContains(err, `no bottle has the metric "f1"`)
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := Compare(entries(), Options{})
+	require.NoError(t, err)
+
+	d, err := c.Diff("a")
+	require.NoError(t, err)
+	assert.Equal("a", d.Baseline)
+	assert.Equal([]string{"b", "c"}, d.Bottles)
+	require.Len(t, d.Rows, 4)
+
+	accuracy := d.Rows[0]
+	assert.InDelta(0.91, *accuracy.Baseline, 1e-9)
+	assert.Equal(Better, accuracy.Deltas[0].Change)
+	assert.InDelta(0.02, *accuracy.Deltas[0].Delta, 1e-9)
+
+	epochs := d.Rows[1]
+	assert.Nil(epochs.Baseline)
+	assert.Equal(Missing, epochs.Deltas[1].Change)
+	assert.Nil(epochs.Deltas[1].Delta)
+
+	latency := d.Rows[2]
+	assert.Equal(Better, latency.Deltas[0].Change)
+	assert.InDelta(-20, *latency.Deltas[0].Delta, 1e-9)
+
+	loss := d.Rows[3]
+	assert.Equal(Better, loss.Deltas[0].Change)
+	assert.Equal(Missing, loss.Deltas[1].Change)
+
+	d, err = c.Diff("c")
+	require.NoError(t, err)
+	assert.Equal(Worse, d.Rows[0].Deltas[0].Change)
+	assert.Equal(Same, d.Rows[0].Deltas[1].Change)
+
+	_, err = c.Diff("z")
+	assert.ErrorContains(err, "unknown baseline")
+}
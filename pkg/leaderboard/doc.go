@@ -0,0 +1,7 @@
+// Package leaderboard compares bottles by their metrics.
+// Compare aligns the metrics of several bottles by name (normalizing the names and applying aliases such as "acc" for
+// "accuracy"), parses their values (see the metric package) and converts them to a common unit.  The resulting
+// Comparison lists every metric with its value in each bottle and flags the bottles it is missing from.  It produces a
+// ranked Leaderboard for one metric and a Diff against a baseline bottle.  Each of them can be written as JSON, CSV or
+// Markdown (see Write).
+package leaderboard
@@ -0,0 +1,185 @@
+package leaderboard
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	// FormatJSON writes the result itself as indented JSON
+	FormatJSON Format = "json"
+
+	// FormatCSV writes the table of the result as CSV
+	FormatCSV Format = "csv"
+
+	// FormatMarkdown writes the table of the result as a Markdown (GitHub flavored) table
+	FormatMarkdown Format = "markdown"
+)
+
+// Table is the tabular form of a result
+type Table struct {
+	// Header has the column names
+	Header []string
+
+	// Rows of cells (each with as many cells as the header)
+	Rows [][]string
+}
+
+// Tabular is implemented by the results (Comparison, Leaderboard and Diff)
+type Tabular interface {
+	Table() Table
+}
+
+// Write writes the result in the format
+func Write(w io.Writer, format Format, v Tabular) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case FormatCSV:
+		t := v.Table()
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.Header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.Rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatMarkdown:
+		return writeMarkdown(w, v.Table())
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// writeMarkdown writes the table as a Markdown table
+func writeMarkdown(w io.Writer, t Table) error {
+	escaper := strings.NewReplacer("|", `\|`, "\n", " ")
+	line := func(cells []string) string {
+		escaped := make([]string, len(cells))
+		for i, c := range cells {
+			escaped[i] = escaper.Replace(c)
+		}
+		return "| " + strings.Join(escaped, " | ") + " |\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(line(t.Header))
+	sep := make([]string, len(t.Header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	sb.WriteString(line(sep))
+	for _, row := range t.Rows {
+		sb.WriteString(line(row))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// formatValue formats the value (empty if it is nil).
+// The tables use 12 significant digits so differences do not show floating point noise (e.g., 0.93 - 0.91).
+func formatValue(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'g', 12, 64)
+}
+
+// withUnit returns the column name with the unit (if any)
+func withUnit(name, unit string) string {
+	if unit == "" || unit == "1" {
+		return name
+	}
+	return name + " (" + unit + ")"
+}
+
+// withOwnUnit formats the value followed by its unit (for the values of incompatible rows)
+func withOwnUnit(v *float64, unit string) string {
+	if v == nil {
+		return ""
+	}
+	return strings.TrimSpace(formatValue(v) + " " + unit)
+}
+
+// Table implements Tabular.  There is a row for each metric with its value in each bottle and the bottles it is
+// missing from.
+func (c *Comparison) Table() Table {
+	t := Table{Header: append(append([]string{"Metric", "Unit", "Direction"}, c.Bottles...), "Missing")}
+	for _, r := range c.Rows {
+		row := []string{r.Metric, r.Unit, string(r.Direction)}
+		for _, v := range r.Values {
+			switch {
+			case v == nil:
+				row = append(row, "")
+			case r.Incompatible:
+				// the value is in its own unit
+				row = append(row, withOwnUnit(&v.Value, v.Unit))
+			default:
+				row = append(row, formatValue(&v.Value))
+			}
+		}
+		if r.Incompatible {
+			row[1], row[2] = "", "incompatible"
+		}
+		t.Rows = append(t.Rows, append(row, strings.Join(r.Missing, ", ")))
+	}
+	return t
+}
+
+// Table implements Tabular.  The ranked bottles are followed by the missing bottles (with "-" as their rank).
+func (lb *Leaderboard) Table() Table {
+	t := Table{Header: []string{"Rank", "Bottle", withUnit(lb.Metric, lb.Unit), "Interval"}}
+	for _, s := range lb.Standings {
+		interval := ""
+		if s.Interval != nil {
+			interval = "[" + formatValue(&s.Interval.Lower) + ", " + formatValue(&s.Interval.Upper) + "]"
+		}
+		t.Rows = append(t.Rows, []string{strconv.Itoa(s.Rank), s.Bottle, formatValue(&s.Value), interval})
+	}
+	for _, m := range lb.Missing {
+		t.Rows = append(t.Rows, []string{"-", m, "", ""})
+	}
+	return t
+}
+
+// Table implements Tabular.  Each bottle has a column with its values and a column with the change from the baseline
+// (e.g., "+0.02 (better)").
+func (d *Diff) Table() Table {
+	t := Table{Header: []string{"Metric", "Unit", d.Baseline + " (baseline)"}}
+	for _, b := range d.Bottles {
+		t.Header = append(t.Header, b, "Δ "+b)
+	}
+	for _, r := range d.Rows {
+		row := []string{r.Metric, r.Unit, formatValue(r.Baseline)}
+		if r.Incompatible {
+			// the values are in their own units
+			row[1], row[2] = "incompatible", withOwnUnit(r.Baseline, r.BaselineUnit)
+		}
+		for _, delta := range r.Deltas {
+			change := string(delta.Change)
+			if delta.Delta != nil {
+				sign := ""
+				if *delta.Delta > 0 {
+					sign = "+"
+				}
+				change = sign + formatValue(delta.Delta) + " (" + change + ")"
+			}
+			value := formatValue(delta.Value)
+			if r.Incompatible {
+				value = withOwnUnit(delta.Value, delta.Unit)
+			}
+			row = append(row, value, change)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
@@ -0,0 +1,84 @@
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	c, err := Compare(entries(), Options{})
+	require.NoError(t, err)
+	lb, err := c.Leaderboard("accuracy")
+	require.NoError(t, err)
+	d, err := c.Diff("a")
+	require.NoError(t, err)
+
+	write := func(format Format, v Tabular) string {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, format, v))
+		return buf.String()
+	}
+
+	assert.Equal(t, `Metric,Unit,Direction,a,b,c,Missing
+accuracy,,higherIsBetter,0.91,0.93,0.93,
+epochs,,,,,12,"a, b"
+latency,ms,lowerIsBetter,120,100,80,
+training loss,,lowerIsBetter,0.3,0.25,,c
+`, write(FormatCSV, c))
+
+	assert.Equal(t, `| Rank | Bottle | accuracy | Interval |
+| --- | --- | --- | --- |
+| 1 | b | 0.93 |  |
+| 1 | c | 0.93 |  |
+| 3 | a | 0.91 |  |
+`, write(FormatMarkdown, lb))
+
+	lb, err = c.Leaderboard("training loss")
+	require.NoError(t, err)
+	assert.Equal(t, `Rank,Bottle,training loss,Interval
+1,b,0.25,
+2,a,0.3,
+-,c,,
+`, write(FormatCSV, lb))
+
+	assert.Equal(t, `| Metric | Unit | a (baseline) | b | Δ b | c | Δ c |
+| --- | --- | --- | --- | --- | --- | --- |
+| accuracy |  | 0.91 | 0.93 | +0.02 (better) | 0.93 | +0.02 (better) |
+| epochs |  |  |  | missing | 12 | missing |
+| latency | ms | 120 | 100 | -20 (better) | 80 | -40 (better) |
+| training loss |  | 0.3 | 0.25 | -0.05 (better) |  | missing |
+`, write(FormatMarkdown, d))
+
+	var decoded Diff
+	require.NoError(t, json.Unmarshal([]byte(write(FormatJSON, d)), &decoded))
+	assert.Equal(t, *d, decoded)
+
+	assert.ErrorContains(t, Write(&bytes.Buffer{}, "xml", c), `unsupported format "xml"`)
+}
+
+func TestWriteMarkdown_Escape(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeMarkdown(&buf, Table{Header: []string{"a|b"}, Rows: [][]string{{"c\nd"}}}))
+	assert.Equal(t, "| a\\|b |\n| --- |\n| c d |\n", buf.String())
+}
+
+func TestDiffTable_Incompatible(t *testing.T) {
+	c, err := Compare([]Entry{
+		{Name: "a", Bottle: newBottle("size", "1")},
+		{Name: "b", Bottle: newBottle("size (ms)", "2")},
+	}, Options{})
+	require.NoError(t, err)
+
+	d, err := c.Diff("a")
+	require.NoError(t, err)
+	require.Len(t, d.Rows, 1)
+	assert.True(t, d.Rows[0].Incompatible)
+	assert.Equal(t, "ms", d.Rows[0].Deltas[0].Unit)
+
+	assert.Equal(t, [][]string{{"size", "incompatible", "1", "2 ms", "changed"}}, d.Table().Rows)
+}
@@ -0,0 +1,226 @@
+package leaderboard
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/act3-ai/bottle-schema/pkg/metric"
+)
+
+// Standing is the position of a bottle in a leaderboard
+type Standing struct {
+	// Rank of the bottle starting at 1 (bottles with equal values have the same rank)
+	Rank int `json:"rank"`
+
+	// Bottle name
+	Bottle string `json:"bottle"`
+
+	// Value of the metric in the unit of the leaderboard
+	Value float64 `json:"value"`
+
+	// Interval is the confidence interval of the value (if known)
+	Interval *metric.Interval `json:"interval,omitempty"`
+}
+
+// Leaderboard ranks the bottles by a metric
+type Leaderboard struct {
+	// Metric is the canonical name of the metric
+	Metric string `json:"metric"`
+
+	// Unit of the values
+	Unit string `json:"unit,omitempty"`
+
+	// Direction in which the metric improves
+	Direction metric.Direction `json:"direction"`
+
+	// Standings from best to worst
+	Standings []Standing `json:"standings"`
+
+	// Missing lists the bottles that do not have the metric (they are not ranked)
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Leaderboard ranks the bottles by the metric.  The direction of the metric must be known and the values must be
+// compatible.
+func (c *Comparison) Leaderboard(name string) (*Leaderboard, error) {
+	row := c.Row(name)
+	switch {
+	case row == nil:
+		return nil, fmt.Errorf("no bottle has the metric %q", name)
+	case row.Incompatible:
+		return nil, fmt.Errorf("the values of metric %q are not comparable (the bottles disagree on the unit or direction)", row.Metric)
+	case row.Direction == "":
+		return nil, fmt.Errorf("direction of metric %q is unknown", row.Metric)
+	}
+
+	lb := &Leaderboard{
+		Metric:    row.Metric,
+		Unit:      row.Unit,
+		Direction: row.Direction,
+		Missing:   row.Missing,
+	}
+	for i, v := range row.Values {
+		if v != nil {
+			lb.Standings = append(lb.Standings, Standing{Bottle: c.Bottles[i], Value: v.Value, Interval: v.Interval})
+		}
+	}
+	better := func(a, b float64) bool {
+		if row.Direction == metric.LowerIsBetter {
+			return a < b
+		}
+		return a > b
+	}
+	slices.SortStableFunc(lb.Standings, func(a, b Standing) int {
+		switch {
+		case better(a.Value, b.Value):
+			return -1
+		case better(b.Value, a.Value):
+			return 1
+		}
+		return 0
+	})
+	for i := range lb.Standings {
+		if i > 0 && lb.Standings[i].Value == lb.Standings[i-1].Value {
+			lb.Standings[i].Rank = lb.Standings[i-1].Rank
+		} else {
+			lb.Standings[i].Rank = i + 1
+		}
+	}
+	return lb, nil
+}
+
+// Change is the change of a metric in a bottle relative to the baseline
+type Change string
+
+const (
+	// Better is used when the metric improved
+	Better Change = "better"
+
+	// Worse is used when the metric regressed
+	Worse Change = "worse"
+
+	// Same is used when the metric did not change
+	Same Change = "same"
+
+	// Changed is used when the metric changed but its direction is unknown (or the values are not comparable)
+	Changed Change = "changed"
+
+	// Missing is used when the metric is missing from the bottle or the baseline
+	Missing Change = "missing"
+)
+
+// Delta is the value of a metric in a bottle compared to the baseline
+type Delta struct {
+	// Bottle name
+	Bottle string `json:"bottle"`
+
+	// Value of the metric (nil if missing)
+	Value *float64 `json:"value"`
+
+	// Unit of the value when the row is incompatible (otherwise the value is in the unit of the row)
+	Unit string `json:"unit,omitempty"`
+
+	// Delta is the value minus the baseline value (nil if either is missing or they are not comparable)
+	Delta *float64 `json:"delta"`
+
+	// Change relative to the baseline
+	Change Change `json:"change"`
+}
+
+// DiffRow is a metric of the bottles compared to the baseline
+type DiffRow struct {
+	// Metric is the canonical name of the metric
+	Metric string `json:"metric"`
+
+	// Unit of the values
+	Unit string `json:"unit,omitempty"`
+
+	// Direction in which the metric improves (empty if unknown)
+	Direction metric.Direction `json:"direction,omitempty"`
+
+	// Baseline is the value of the metric in the baseline (nil if missing)
+	Baseline *float64 `json:"baseline"`
+
+	// BaselineUnit is the unit of the baseline value when the row is incompatible
+	BaselineUnit string `json:"baselineUnit,omitempty"`
+
+	// Deltas of the other bottles (in order)
+	Deltas []Delta `json:"deltas"`
+
+	// Incompatible is set when the bottles disagree on the unit dimension or direction of the metric (see
+	// Row.Incompatible).  The values are left in their own unit and there are no deltas.
+	Incompatible bool `json:"incompatible,omitempty"`
+}
+
+// Diff is the metrics of the bottles compared to a baseline bottle
+type Diff struct {
+	// Baseline is the name of the baseline bottle
+	Baseline string `json:"baseline"`
+
+	// Bottles are the names of the other bottles (in order)
+	Bottles []string `json:"bottles"`
+
+	// Rows are the metrics sorted by name
+	Rows []DiffRow `json:"rows"`
+}
+
+// Diff compares the metrics of the other bottles to the baseline bottle
+func (c *Comparison) Diff(baseline string) (*Diff, error) {
+	b := c.index(baseline)
+	if b < 0 {
+		return nil, fmt.Errorf("unknown baseline bottle %q", baseline)
+	}
+	d := &Diff{Baseline: baseline}
+	for i, name := range c.Bottles {
+		if i != b {
+			d.Bottles = append(d.Bottles, name)
+		}
+	}
+	for _, row := range c.Rows {
+		dr := DiffRow{Metric: row.Metric, Unit: row.Unit, Direction: row.Direction, Incompatible: row.Incompatible}
+		base := row.Values[b]
+		if base != nil {
+			dr.Baseline = &base.Value
+			if row.Incompatible {
+				dr.BaselineUnit = base.Unit
+			}
+		}
+		for i, v := range row.Values {
+			if i == b {
+				continue
+			}
+			delta := Delta{Bottle: c.Bottles[i], Change: Missing}
+			if v != nil {
+				delta.Value = &v.Value
+				if row.Incompatible {
+					delta.Unit = v.Unit
+				}
+			}
+			if v != nil && base != nil {
+				delta.Change = Changed
+				if !row.Incompatible {
+					diff := v.Value - base.Value
+					delta.Delta = &diff
+					delta.Change = change(diff, row.Direction)
+				}
+			}
+			dr.Deltas = append(dr.Deltas, delta)
+		}
+		d.Rows = append(d.Rows, dr)
+	}
+	return d, nil
+}
+
+// change returns the change for the difference from the baseline
+func change(diff float64, direction metric.Direction) Change {
+	switch {
+	case diff == 0:
+		return Same
+	case direction == metric.HigherIsBetter && diff > 0, direction == metric.LowerIsBetter && diff < 0:
+		return Better
+	case direction == "":
+		return Changed
+	default:
+		return Worse
+	}
+}
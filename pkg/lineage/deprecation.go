@@ -0,0 +1,163 @@
+package lineage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// Index finds the bottles that deprecate a bottle (e.g., a Graph or a catalog of bottles)
+type Index interface {
+	// DeprecatedBy returns the bottle IDs of the bottles that deprecate the bottle with the bottle ID id.
+	// Bottles that are not deprecated (or not known) have none.
+	DeprecatedBy(ctx context.Context, id digest.Digest) ([]digest.Digest, error)
+}
+
+// IndexFunc is an adapter to allow the use of an ordinary function as an Index
+type IndexFunc func(ctx context.Context, id digest.Digest) ([]digest.Digest, error)
+
+// DeprecatedBy calls f(ctx, id)
+func (f IndexFunc) DeprecatedBy(ctx context.Context, id digest.Digest) ([]digest.Digest, error) {
+	return f(ctx, id)
+}
+
+// DeprecatedBy implements Index
+func (g *Graph) DeprecatedBy(_ context.Context, id digest.Digest) ([]digest.Digest, error) {
+	return g.SupersededBy(id), nil
+}
+
+// Conflict is a bottle that is deprecated by more than one bottle
+type Conflict struct {
+	// BottleID of the deprecated bottle
+	BottleID digest.Digest
+
+	// Successors are the (sorted) bottle IDs of the bottles that deprecate it
+	Successors []digest.Digest
+}
+
+// DeprecationWarning describes why a bottle is deprecated and what should be used instead
+type DeprecationWarning struct {
+	// BottleID of the deprecated bottle
+	BottleID digest.Digest
+
+	// Latest is the bottle ID of the latest (not deprecated) successor.
+	// It is empty if there is no single latest successor (see Candidates).
+	Latest digest.Digest
+
+	// Chain is the bottle IDs from BottleID to Latest following the deprecations (only when there are no conflicts)
+	Chain []digest.Digest
+
+	// Candidates are the (sorted) bottle IDs of every successor that is not deprecated
+	Candidates []digest.Digest
+
+	// Conflicts are the bottles in the chains that are deprecated by more than one bottle
+	Conflicts []Conflict
+
+	// Cycle is the bottle IDs of a deprecation cycle (where the first bottle deprecates the last) if one was found
+	Cycle []digest.Digest
+}
+
+// ResolveDeprecation follows the deprecations of the bottle with the bottle ID id (transitively) through index to
+// find its latest successor.  It returns nil if the bottle is not deprecated.
+func ResolveDeprecation(ctx context.Context, index Index, id digest.Digest) (*DeprecationWarning, error) {
+	w := &DeprecationWarning{BottleID: id}
+	visited := map[digest.Digest]bool{}
+	onPath := map[digest.Digest]bool{}
+	var path []digest.Digest
+	// order the bottles were first visited (the chain when each bottle has at most one successor)
+	var order []digest.Digest
+
+	var follow func(cur digest.Digest) error
+	follow = func(cur digest.Digest) error {
+		if onPath[cur] {
+			if w.Cycle == nil {
+				w.Cycle = slices.Clone(path[slices.Index(path, cur):])
+			}
+			return nil
+		}
+		if visited[cur] {
+			return nil
+		}
+		visited[cur] = true
+		order = append(order, cur)
+
+		successors, err := index.DeprecatedBy(ctx, cur)
+		if err != nil {
+			return fmt.Errorf("finding the bottles that deprecate %s: %w", cur, err)
+		}
+		successors = slices.Compact(slices.Sorted(slices.Values(successors)))
+		switch len(successors) {
+		case 0:
+			w.Candidates = append(w.Candidates, cur)
+			return nil
+		case 1:
+		default:
+			w.Conflicts = append(w.Conflicts, Conflict{BottleID: cur, Successors: successors})
+		}
+
+		onPath[cur] = true
+		path = append(path, cur)
+		for _, s := range successors {
+			if err := follow(s); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		onPath[cur] = false
+		return nil
+	}
+
+	if err := follow(id); err != nil {
+		return nil, err
+	}
+	if len(w.Candidates) == 1 && w.Candidates[0] == id && w.Cycle == nil {
+		// not deprecated
+		return nil, nil
+	}
+
+	slices.Sort(w.Candidates)
+	if len(w.Candidates) == 1 {
+		w.Latest = w.Candidates[0]
+	}
+	if w.Latest != "" && w.Conflicts == nil && w.Cycle == nil {
+		// the chain is linear
+		w.Chain = order
+	}
+	return w, nil
+}
+
+// String returns the warning to show when the deprecated bottle is used
+func (w *DeprecationWarning) String() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "bottle %s is deprecated", w.BottleID)
+	switch {
+	case w.Latest != "":
+		fmt.Fprintf(sb, "; use %s instead", w.Latest)
+	case len(w.Candidates) > 0:
+		fmt.Fprintf(sb, "; there are multiple successors: %s", joinIDs(w.Candidates, ", "))
+	default:
+		sb.WriteString("; there is no successor that is not deprecated")
+	}
+	if len(w.Chain) > 2 {
+		fmt.Fprintf(sb, " (superseded by %s)", joinIDs(w.Chain[1:], " -> "))
+	}
+	for _, c := range w.Conflicts {
+		fmt.Fprintf(sb, "\n  bottle %s is deprecated by multiple bottles: %s", c.BottleID, joinIDs(c.Successors, ", "))
+	}
+	if w.Cycle != nil {
+		fmt.Fprintf(sb, "\n  deprecation cycle: %s -> %s", joinIDs(w.Cycle, " -> "), w.Cycle[0])
+	}
+	return sb.String()
+}
+
+// joinIDs joins the bottle IDs with sep
+func joinIDs(ids []digest.Digest, sep string) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,120 @@
+package lineage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDeprecation(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	id := func(s string) digest.Digest {
+		return digest.FromString(s)
+	}
+	// deprecatedBy maps each bottle to the bottles that deprecate it
+	index := func(deprecatedBy map[string][]string) Index {
+		return IndexFunc(func(_ context.Context, dgst digest.Digest) ([]digest.Digest, error) {
+			for k, v := range deprecatedBy {
+				if id(k) == dgst {
+					ids := make([]digest.Digest, len(v))
+					for i, s := range v {
+						ids[i] = id(s)
+					}
+					return ids, nil
+				}
+			}
+			return nil, nil
+		})
+	}
+
+	// not deprecated
+	w, err := ResolveDeprecation(ctx, index(nil), id("a"))
+	assert.NoError(err)
+	assert.Nil(w)
+
+	// linear chain
+	w, err = ResolveDeprecation(ctx, index(map[string][]string{"a": {"b"}, "b": {"c"}}), id("a"))
+	assert.NoError(err)
+	assert.Equal(&DeprecationWarning{
+		BottleID:   id("a"),
+		Latest:     id("c"),
+		Chain:      []digest.Digest{id("a"), id("b"), id("c")},
+		Candidates: []digest.Digest{id("c")},
+	}, w)
+	assert.Equal("bottle "+id("a").String()+" is deprecated; use "+id("c").String()+" instead (superseded by "+
+		id("b").String()+" -> "+id("c").String()+")", w.String())
+
+	// conflicting successors
+	w, err = ResolveDeprecation(ctx, index(map[string][]string{"a": {"b", "c"}}), id("a"))
+	assert.NoError(err)
+	assert.Empty(w.Latest)
+	assert.Nil(w.Chain)
+	assert.ElementsMatch([]digest.Digest{id("b"), id("c")}, w.Candidates)
+	if assert.Len(w.Conflicts, 1) {
+		assert.Equal(id("a"), w.Conflicts[0].BottleID)
+		assert.ElementsMatch([]digest.Digest{id("b"), id("c")}, w.Conflicts[0].Successors)
+	}
+	assert.Contains(w.String(), "there are multiple successors")
+	assert.Contains(w.String(), "is deprecated by multiple bottles")
+
+	// conflicting successors that converge
+	w, err = ResolveDeprecation(ctx, index(map[string][]string{"a": {"b", "c"}, "b": {"d"}, "c": {"d"}}), id("a"))
+	assert.NoError(err)
+	assert.Equal(id("d"), w.Latest)
+	assert.Nil(w.Chain)
+	assert.Len(w.Conflicts, 1)
+
+	// cycle
+	w, err = ResolveDeprecation(ctx, index(map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}}), id("a"))
+	assert.NoError(err)
+	assert.Empty(w.Latest)
+	assert.Empty(w.Candidates)
+	assert.Equal([]digest.Digest{id("b"), id("c")}, w.Cycle)
+	assert.Contains(w.String(), "no successor that is not deprecated")
+	assert.Contains(w.String(), "deprecation cycle: "+id("b").String()+" -> "+id("c").String()+" -> "+id("b").String())
+
+	// each bottle is looked up once (the index may change between lookups)
+	calls := map[digest.Digest]int{}
+	w, err = ResolveDeprecation(ctx, IndexFunc(func(_ context.Context, dgst digest.Digest) ([]digest.Digest, error) {
+		calls[dgst]++
+		if dgst == id("a") && calls[dgst] == 1 {
+			return []digest.Digest{id("b")}, nil
+		}
+		return nil, nil
+	}), id("a"))
+	assert.NoError(err)
+	assert.Equal([]digest.Digest{id("a"), id("b")}, w.Chain)
+	assert.Equal(map[digest.Digest]int{id("a"): 1, id("b"): 1}, calls)
+
+	// errors
+	_, err = ResolveDeprecation(ctx, IndexFunc(func(context.Context, digest.Digest) ([]digest.Digest, error) {
+		return nil, errors.New("offline")
+	}), id("a"))
+	assert.ErrorContains(err, "offline")
+}
+
+func TestGraph_DeprecatedBy(t *testing.T) {
+	assert := assert.New(t)
+
+	g := New()
+	v1ID, err := g.Add(newBottle("v1", nil))
+	assert.NoError(err)
+	v2ID, err := g.Add(newBottle("v2", nil, v1ID))
+	assert.NoError(err)
+	v3ID, err := g.Add(newBottle("v3", nil, v2ID))
+	assert.NoError(err)
+
+	w, err := ResolveDeprecation(context.Background(), g, v1ID)
+	assert.NoError(err)
+	assert.Equal(v3ID, w.Latest)
+	assert.Equal([]digest.Digest{v1ID, v2ID, v3ID}, w.Chain)
+
+	b, ok := g.Bottle(w.Latest)
+	assert.True(ok)
+	assert.Equal([]digest.Digest{v2ID}, b.Deprecates)
+}
@@ -0,0 +1,8 @@
+// Package lineage builds the graph of the relationships between bottles.
+// Bottles reference the bottles they were derived from through their sources (bottle: and hash:// URIs, possibly with
+// part selectors) and the bottles they supersede through deprecates.  The graph is keyed by bottle ID and answers
+// ancestry and supersession queries.  It can be exported as DOT (for Graphviz) or JSON.
+//
+// ResolveDeprecation follows deprecations (through any Index, including a Graph) to find the latest successor of a
+// deprecated bottle.
+package lineage
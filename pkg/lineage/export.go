@@ -0,0 +1,97 @@
+package lineage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// selectorStrings returns the string form of each selector of the edge (nil selects every part)
+func (e Edge) selectorStrings() []string {
+	if e.Selectors == nil {
+		return nil
+	}
+	sels := make([]string, len(e.Selectors))
+	for i, s := range e.Selectors {
+		sels[i] = s.String()
+	}
+	return sels
+}
+
+// shortID returns the bottle ID abbreviated for display
+func shortID(id digest.Digest) string {
+	encoded := id.Encoded()
+	if len(encoded) > 12 {
+		encoded = encoded[:12]
+	}
+	return id.Algorithm().String() + ":" + encoded
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language to w.
+// Bottles that were not added are dashed and deprecates edges are red.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph lineage {")
+	fmt.Fprintln(bw, "  rankdir=BT;")
+	fmt.Fprintln(bw, "  node [shape=box];")
+	for _, id := range g.Nodes() {
+		attrs := "label=" + strconv.Quote(shortID(id))
+		if _, ok := g.Bottle(id); !ok {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", strconv.Quote(id.String()), attrs)
+	}
+	for _, e := range g.edges {
+		label := string(e.Kind)
+		if sels := e.selectorStrings(); sels != nil {
+			label += "\n" + strings.Join(sels, "|")
+		}
+		attrs := "label=" + strconv.Quote(label)
+		if e.Kind == Deprecates {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(bw, "  %s -> %s [%s];\n", strconv.Quote(e.From.String()), strconv.Quote(e.To.String()), attrs)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+type jsonNode struct {
+	ID         digest.Digest `json:"id"`
+	Added      bool          `json:"added"`
+	Superseded bool          `json:"superseded,omitempty"`
+}
+
+type jsonEdge struct {
+	From      digest.Digest `json:"from"`
+	To        digest.Digest `json:"to"`
+	Kind      EdgeKind      `json:"kind"`
+	Source    string        `json:"source,omitempty"`
+	Selectors []string      `json:"selectors,omitempty"`
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+// MarshalJSON encodes the nodes (sorted by bottle ID) and the edges of the graph
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	out := jsonGraph{
+		Nodes: []jsonNode{},
+		Edges: make([]jsonEdge, len(g.edges)),
+	}
+	for _, id := range g.Nodes() {
+		_, added := g.Bottle(id)
+		out.Nodes = append(out.Nodes, jsonNode{ID: id, Added: added, Superseded: g.IsSuperseded(id)})
+	}
+	for i, e := range g.edges {
+		out.Edges[i] = jsonEdge{From: e.From, To: e.To, Kind: e.Kind, Source: e.Source, Selectors: e.selectorStrings()}
+	}
+	return json.Marshal(out)
+}
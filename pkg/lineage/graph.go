@@ -0,0 +1,267 @@
+package lineage
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/opencontainers/go-digest"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	"github.com/act3-ai/bottle-schema/pkg/selectors"
+	"github.com/act3-ai/bottle-schema/pkg/util"
+)
+
+// EdgeKind is the kind of relationship between two bottles
+type EdgeKind string
+
+const (
+	// DerivedFrom edges point from a bottle to a bottle in its sources
+	DerivedFrom EdgeKind = "derivedFrom"
+
+	// Deprecates edges point from a bottle to a bottle it deprecates (supersedes)
+	Deprecates EdgeKind = "deprecates"
+)
+
+// Edge is a relationship from one bottle to another
+type Edge struct {
+	// From is the bottle ID of the bottle declaring the relationship
+	From digest.Digest
+
+	// To is the bottle ID of the referenced bottle
+	To digest.Digest
+
+	// Kind of relationship
+	Kind EdgeKind
+
+	// Source is the name of the source (only for DerivedFrom edges)
+	Source string
+
+	// Selectors are the part selectors of the source (only for DerivedFrom edges).  Nil selects every part.
+	Selectors selectors.LabelSelectorSet
+}
+
+// Graph is the lineage graph of bottles.
+// Bottles that are referenced but have not been added are also nodes (without a bottle).
+type Graph struct {
+	bottles map[digest.Digest]*v1.Bottle
+	edges   []Edge
+
+	// out and in are the indices of the edges from and to each node
+	out map[digest.Digest][]int
+	in  map[digest.Digest][]int
+}
+
+// New returns an empty graph
+func New() *Graph {
+	return &Graph{
+		bottles: map[digest.Digest]*v1.Bottle{},
+		out:     map[digest.Digest][]int{},
+		in:      map[digest.Digest][]int{},
+	}
+}
+
+// Build returns the graph of the bottles (keyed by bottle ID)
+func Build(bottles map[digest.Digest]*v1.Bottle) (*Graph, error) {
+	g := New()
+	ids := make([]digest.Digest, 0, len(bottles))
+	for id := range bottles {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		if err := g.AddWithID(id, bottles[id]); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Add adds the bottle (with the canonical bottle ID) and its relationships to the graph and returns its bottle ID
+func (g *Graph) Add(bottle *v1.Bottle) (digest.Digest, error) {
+	id, err := bottle.BottleID(digest.Canonical)
+	if err != nil {
+		return "", err
+	}
+	return id, g.AddWithID(id, bottle)
+}
+
+// AddWithID adds the bottle with the bottle ID id and its relationships to the graph.
+// Sources that are not bottle references are ignored.
+func (g *Graph) AddWithID(id digest.Digest, bottle *v1.Bottle) error {
+	if err := id.Validate(); err != nil {
+		return fmt.Errorf("bottle ID %q: %w", id, err)
+	}
+	if g.bottles[id] != nil {
+		return fmt.Errorf("bottle %s has already been added", id)
+	}
+
+	var edges []Edge
+	for i, s := range bottle.Sources {
+		to, sels, err := util.ParseSourceURI(s.URI)
+		if err != nil {
+			return fmt.Errorf("bottle %s: source %d (%s): %w", id, i, s.Name, err)
+		}
+		if to == "" {
+			continue
+		}
+		edges = append(edges, Edge{From: id, To: to, Kind: DerivedFrom, Source: s.Name, Selectors: sels})
+	}
+	for _, to := range bottle.Deprecates {
+		edges = append(edges, Edge{From: id, To: to, Kind: Deprecates})
+	}
+
+	g.bottles[id] = bottle
+	for _, e := range edges {
+		if _, ok := g.bottles[e.To]; !ok {
+			g.bottles[e.To] = nil
+		}
+		g.out[e.From] = append(g.out[e.From], len(g.edges))
+		g.in[e.To] = append(g.in[e.To], len(g.edges))
+		g.edges = append(g.edges, e)
+	}
+	return nil
+}
+
+// Nodes returns the (sorted) bottle IDs of every node in the graph (including referenced bottles that were not added)
+func (g *Graph) Nodes() []digest.Digest {
+	ids := make([]digest.Digest, 0, len(g.bottles))
+	for id := range g.bottles {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Bottle returns the bottle with the bottle ID.  It returns false if the bottle was not added.
+func (g *Graph) Bottle(id digest.Digest) (*v1.Bottle, bool) {
+	b := g.bottles[id]
+	return b, b != nil
+}
+
+// Edges returns every edge in the order they were added
+func (g *Graph) Edges() []Edge {
+	return slices.Clone(g.edges)
+}
+
+// Parents returns the (sorted) bottle IDs that the bottle was directly derived from
+func (g *Graph) Parents(id digest.Digest) []digest.Digest {
+	return g.neighbors(g.out[id], DerivedFrom, func(e Edge) digest.Digest { return e.To })
+}
+
+// Children returns the (sorted) bottle IDs that were directly derived from the bottle
+func (g *Graph) Children(id digest.Digest) []digest.Digest {
+	return g.neighbors(g.in[id], DerivedFrom, func(e Edge) digest.Digest { return e.From })
+}
+
+// Ancestors returns the (sorted) bottle IDs that the bottle was derived from (directly or indirectly)
+func (g *Graph) Ancestors(id digest.Digest) []digest.Digest {
+	return g.reachable(id, g.Parents)
+}
+
+// Descendants returns the (sorted) bottle IDs that were derived from the bottle (directly or indirectly)
+func (g *Graph) Descendants(id digest.Digest) []digest.Digest {
+	return g.reachable(id, g.Children)
+}
+
+// SupersededBy returns the (sorted) bottle IDs of the bottles that deprecate the bottle
+func (g *Graph) SupersededBy(id digest.Digest) []digest.Digest {
+	return g.neighbors(g.in[id], Deprecates, func(e Edge) digest.Digest { return e.From })
+}
+
+// IsSuperseded returns true if any bottle in the graph deprecates the bottle
+func (g *Graph) IsSuperseded(id digest.Digest) bool {
+	return len(g.SupersededBy(id)) > 0
+}
+
+// neighbors returns the sorted and unique nodes (selected by node) of the edges of the given kind
+func (g *Graph) neighbors(edges []int, kind EdgeKind, node func(Edge) digest.Digest) []digest.Digest {
+	var ids []digest.Digest
+	for _, i := range edges {
+		if e := g.edges[i]; e.Kind == kind {
+			ids = append(ids, node(e))
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// reachable returns the sorted nodes reachable from id through next (excluding id unless it is in a cycle)
+func (g *Graph) reachable(id digest.Digest, next func(digest.Digest) []digest.Digest) []digest.Digest {
+	visited := map[digest.Digest]bool{}
+	var ids []digest.Digest
+	queue := next(id)
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+		ids = append(ids, n)
+		queue = append(queue, next(n)...)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Cycles returns the cycles in the graph (considering every kind of edge).
+// Each cycle is the sorted bottle IDs of a strongly connected component with more than one node (or a node with an
+// edge to itself).  Bottle IDs are content digests so cycles indicate bottles that were added with the wrong ID.
+func (g *Graph) Cycles() [][]digest.Digest {
+	// Tarjan's strongly connected components algorithm
+	index := map[digest.Digest]int{}
+	lowlink := map[digest.Digest]int{}
+	onStack := map[digest.Digest]bool{}
+	var stack []digest.Digest
+	var cycles [][]digest.Digest
+
+	var connect func(v digest.Digest)
+	connect = func(v digest.Digest) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		selfLoop := false
+		for _, i := range g.out[v] {
+			w := g.edges[i].To
+			if w == v {
+				selfLoop = true
+			}
+			if _, ok := index[w]; !ok {
+				connect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] != index[v] {
+			return
+		}
+		var component []digest.Digest
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			slices.Sort(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, id := range g.Nodes() {
+		if _, ok := index[id]; !ok {
+			connect(id)
+		}
+	}
+	slices.SortFunc(cycles, func(a, b []digest.Digest) int {
+		return slices.Compare(a, b)
+	})
+	return cycles
+}
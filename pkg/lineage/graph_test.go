@@ -0,0 +1,140 @@
+package lineage
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+)
+
+// newBottle returns a bottle with the given sources (URIs) that deprecates the given bottles
+func newBottle(description string, uris []string, deprecates ...digest.Digest) *v1.Bottle {
+	b := v1.NewBottle()
+	b.Description = description
+	for _, uri := range uris {
+		b.Sources = append(b.Sources, v1.Source{Name: "src", URI: uri})
+	}
+	b.Deprecates = deprecates
+	return &b
+}
+
+func TestGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	raw := digest.FromString("raw data")
+	g := New()
+	dataset, err := g.Add(newBottle("dataset", []string{
+		"bottle:" + raw.String(),
+		"https://example.com/dataset",
+	}))
+	require.NoError(t, err)
+	model, err := g.Add(newBottle("model", []string{
+		"hash://sha256/" + dataset.Encoded() + "?type=application%2Fvnd.act3-ace.bottle.config.v1%2Bjson&selector=subset%3Dtrain",
+	}))
+	require.NoError(t, err)
+	model2, err := g.Add(newBottle("model v2", []string{"bottle:" + dataset.String()}, model))
+	require.NoError(t, err)
+	eval, err := g.Add(newBottle("evaluation", []string{"bottle:" + model.String()}))
+	require.NoError(t, err)
+
+	_, err = g.Add(newBottle("model", []string{
+		"hash://sha256/" + dataset.Encoded() + "?type=application%2Fvnd.act3-ace.bottle.config.v1%2Bjson&selector=subset%3Dtrain",
+	}))
+	assert.ErrorContains(err, "already been added")
+
+	assert.ElementsMatch([]digest.Digest{raw, dataset, model, model2, eval}, g.Nodes())
+	_, ok := g.Bottle(raw)
+	assert.False(ok)
+	b, ok := g.Bottle(model)
+	assert.True(ok)
+	assert.Equal("model", b.Description)
+
+	edges := g.Edges()
+	assert.Len(edges, 5)
+	assert.Equal(Edge{From: dataset, To: raw, Kind: DerivedFrom, Source: "src"}, edges[0])
+	assert.Equal(DerivedFrom, edges[1].Kind)
+	if assert.Len(edges[1].Selectors, 1) {
+		assert.Equal("subset=train", edges[1].Selectors[0].String())
+	}
+
+	assert.Equal([]digest.Digest{dataset}, g.Parents(model))
+	assert.ElementsMatch([]digest.Digest{raw, dataset, model}, g.Ancestors(eval))
+	assert.IsNonDecreasing(g.Ancestors(eval))
+	assert.ElementsMatch([]digest.Digest{model, model2, eval}, g.Descendants(dataset))
+	assert.ElementsMatch([]digest.Digest{model, model2}, g.Children(dataset))
+	assert.Empty(g.Ancestors(raw))
+
+	assert.True(g.IsSuperseded(model))
+	assert.Equal([]digest.Digest{model2}, g.SupersededBy(model))
+	assert.False(g.IsSuperseded(model2))
+
+	assert.Empty(g.Cycles())
+
+	// export
+	buf := &bytes.Buffer{}
+	assert.NoError(g.WriteDOT(buf))
+	dot := buf.String()
+	assert.Contains(dot, "digraph lineage {")
+	assert.Contains(dot, `"`+dataset.String()+`" -> "`+raw.String()+`" [label="derivedFrom"];`)
+	assert.Contains(dot, `[label="derivedFrom\nsubset=train"]`)
+	assert.Contains(dot, `"`+model2.String()+`" -> "`+model.String()+`" [label="deprecates", color=red];`)
+	assert.Contains(dot, `"`+raw.String()+`" [label="`+shortID(raw)+`", style=dashed];`)
+
+	data, err := json.Marshal(g)
+	assert.NoError(err)
+	var decoded struct {
+		Nodes []struct {
+			ID         digest.Digest `json:"id"`
+			Added      bool          `json:"added"`
+			Superseded bool          `json:"superseded"`
+		} `json:"nodes"`
+		Edges []struct {
+			Kind      EdgeKind `json:"kind"`
+			Selectors []string `json:"selectors"`
+		} `json:"edges"`
+	}
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Len(decoded.Nodes, 5)
+	assert.Len(decoded.Edges, 5)
+	assert.Equal([]string{"subset=train"}, decoded.Edges[1].Selectors)
+	for _, n := range decoded.Nodes {
+		assert.Equal(n.ID != raw, n.Added, n.ID)
+		assert.Equal(n.ID == model, n.Superseded, n.ID)
+	}
+}
+
+func TestGraph_Cycles(t *testing.T) {
+	assert := assert.New(t)
+
+	a := digest.FromString("a")
+	b := digest.FromString("b")
+	c := digest.FromString("c")
+	d := digest.FromString("d")
+	// the IDs do not match the content so cycles are possible
+	g, err := Build(map[digest.Digest]*v1.Bottle{
+		a: newBottle("a", []string{"bottle:" + b.String()}),
+		b: newBottle("b", nil, c),
+		c: newBottle("c", []string{"bottle:" + a.String()}),
+		d: newBottle("d", []string{"bottle:" + d.String(), "bottle:" + a.String()}),
+	})
+	assert.NoError(err)
+
+	abc := []digest.Digest{a, b, c}
+	slices.Sort(abc)
+	assert.ElementsMatch([][]digest.Digest{abc, {d}}, g.Cycles())
+
+	// traversals terminate (ancestors only follow derivedFrom edges)
+	assert.Equal([]digest.Digest{b}, g.Ancestors(a))
+	assert.Contains(g.Ancestors(d), d)
+
+	_, err = Build(map[digest.Digest]*v1.Bottle{
+		a: newBottle("a", []string{"bottle:sha256:bad"}),
+	})
+	assert.ErrorContains(err, "source 0 (src)")
+}
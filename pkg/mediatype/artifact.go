@@ -1,8 +1,14 @@
 package mediatype
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
 	"mime"
+	"net/http"
 	"path/filepath"
+	"strings"
 )
 
 const (
@@ -13,14 +19,120 @@ const (
 
 	// JupyterNotebookExtension indicates an interactive python notebook
 	JupyterNotebookExtension = ".ipynb"
+
+	// ParquetMediaType is the IANA registered media type of Apache Parquet files
+	ParquetMediaType = "application/vnd.apache.parquet"
+
+	// SafetensorsMediaType is the local media type of safetensors (model weights) files
+	SafetensorsMediaType = "application/x.safetensors"
+
+	// ONNXMediaType is the local media type of ONNX (Open Neural Network Exchange) models
+	ONNXMediaType = "application/x.onnx"
+
+	// HDF5MediaType is the (commonly used) media type of HDF5 files
+	HDF5MediaType = "application/x-hdf5"
+
+	// NumpyMediaType is the (commonly used) media type of NumPy array (.npy) files
+	NumpyMediaType = "application/x-npy"
 )
 
-// DetermineType read the file extension of the file, and returns a type to be included when creating a public artifact
+// extensionTypes are the media types of file extensions not known to the mime package (or known incorrectly)
+var extensionTypes = map[string]string{
+	JupyterNotebookExtension: JupyterNotebookMediaType,
+	".parquet":               ParquetMediaType,
+	".safetensors":           SafetensorsMediaType,
+	".onnx":                  ONNXMediaType,
+	".h5":                    HDF5MediaType,
+	".hdf5":                  HDF5MediaType,
+	".hdf":                   HDF5MediaType,
+	".npy":                   NumpyMediaType,
+}
+
+// sniffLen is the number of bytes used to detect the content type (the same as http.DetectContentType)
+const sniffLen = 512
+
+// DetermineType read the file extension of the file, and returns a type to be included when creating a public artifact.
+// The conversions rely on the result (it affects the bottle ID of converted bottles) so it must not change; new media
+// types are only added to DetectType.
 func DetermineType(path string) string {
 	fExt := filepath.Ext(path)
 	if fExt == JupyterNotebookExtension {
 		return JupyterNotebookMediaType
 	}
-	// TODO we could also consider using http.DetectContentType(buf) to use the contents of the file to determine the content type
 	return mime.TypeByExtension(fExt)
 }
+
+// extensionType returns the media type of the file extension of path (ignoring case)
+func extensionType(path string) string {
+	fExt := strings.ToLower(filepath.Ext(path))
+	if mediaType, ok := extensionTypes[fExt]; ok {
+		return mediaType
+	}
+	return mime.TypeByExtension(fExt)
+}
+
+// DetectType returns the media type of the file with the given path and content (r) to be included when creating a
+// public artifact.  Only the start of r is read.
+// The known ML/data formats are detected from their content (or their extension when they have no signature, e.g.,
+// ONNX), then the file extension is used, and finally http.DetectContentType is applied to the content.
+func DetectType(path string, r io.Reader) (string, error) {
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	buf = buf[:n]
+
+	if mediaType := detectFormat(buf); mediaType != "" {
+		return mediaType, nil
+	}
+	if mediaType := extensionType(path); mediaType != "" {
+		return mediaType, nil
+	}
+	return http.DetectContentType(buf), nil
+}
+
+var (
+	parquetMagic = []byte("PAR1")
+	hdf5Magic    = []byte("\x89HDF\r\n\x1a\n")
+	numpyMagic   = []byte("\x93NUMPY")
+)
+
+// detectFormat returns the media type of the known ML/data format of the content (or "" if not known)
+func detectFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, parquetMagic):
+		return ParquetMediaType
+	case bytes.HasPrefix(data, hdf5Magic):
+		return HDF5MediaType
+	case bytes.HasPrefix(data, numpyMagic):
+		return NumpyMediaType
+	case isSafetensors(data):
+		return SafetensorsMediaType
+	case isNotebook(data):
+		return JupyterNotebookMediaType
+	}
+	return ""
+}
+
+// isSafetensors returns true if the content starts with a safetensors header (the little endian length of the JSON
+// header followed by the header)
+func isSafetensors(data []byte) bool {
+	if len(data) < 10 {
+		return false
+	}
+	// the header is limited to 100MB by the format
+	n := binary.LittleEndian.Uint64(data)
+	return n >= 2 && n <= 100<<20 && data[8] == '{' && (data[9] == '"' || data[9] == '}')
+}
+
+// isNotebook returns true if the content is JSON that starts like a Jupyter notebook (the first key is "cells", as
+// written by Jupyter, or the format is given early)
+func isNotebook(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if !bytes.HasPrefix(data, []byte("{")) {
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimLeft(data[1:], " \t\r\n"), []byte(`"cells"`)) ||
+		bytes.Contains(data, []byte(`"nbformat"`))
+}
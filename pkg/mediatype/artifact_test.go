@@ -0,0 +1,62 @@
+package mediatype
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetermineType(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(JupyterNotebookMediaType, DetermineType("notebooks/train.ipynb"))
+	assert.Equal("application/json", DetermineType("metrics.json"))
+	// the result must not change since the bottle ID of converted bottles depends on it
+	assert.NotEqual(ONNXMediaType, DetermineType("model.onnx"))
+	assert.NotEqual(JupyterNotebookMediaType, DetermineType("train.IPYNB"))
+	assert.Equal("", DetermineType("README"))
+}
+
+func TestDetectType(t *testing.T) {
+	safetensors := func(header string) string {
+		buf := binary.LittleEndian.AppendUint64(nil, uint64(len(header)))
+		return string(buf) + header
+	}
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"parquet", "data", "PAR1\x15\x04", ParquetMediaType},
+		{"hdf5", "model.bin", "\x89HDF\r\n\x1a\n\x00\x00", HDF5MediaType},
+		{"npy", "array", "\x93NUMPY\x01\x00v\x00{'descr'", NumpyMediaType},
+		{"safetensors", "model", safetensors(`{"weight":{"dtype":"F32"}}`), SafetensorsMediaType},
+		{"safetensors empty", "model", safetensors(`{}`), SafetensorsMediaType},
+		{"notebook", "nb.json", "{\n \"cells\": [\n  {\n   \"cell_type\": \"markdown\"", JupyterNotebookMediaType},
+		{"notebook extension", "train.ipynb", "{}", JupyterNotebookMediaType},
+		{"onnx extension", "model.onnx", "\x08\x07\x12\x07pytorch", ONNXMediaType},
+		{"extension ignoring case", "weights.H5", "not hdf5", HDF5MediaType},
+		{"parquet extension", "data.PARQUET", "PAR", ParquetMediaType},
+		{"extension", "report.html", "a,b\n1,2\n", "text/html; charset=utf-8"},
+		{"json", "metrics.json", `{"accuracy": 0.9}`, "application/json"},
+		{"sniffed png", "figure", "\x89PNG\r\n\x1a\n", "image/png"},
+		{"sniffed text", "README", "# Title\n", "text/plain; charset=utf-8"},
+		{"empty", "unknown", "", "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectType(tt.path, strings.NewReader(tt.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// only the start is read
+	r := bytes.NewReader(make([]byte, 2*sniffLen))
+	_, err := DetectType("zeros", r)
+	assert.NoError(t, err)
+	assert.Equal(t, sniffLen, r.Len())
+}
@@ -0,0 +1,130 @@
+package metric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+// AnnotationMetrics is the bottle annotation that records the details of the bottle's metrics.
+// The value is a JSON object mapping the metric names to their Details (e.g., {"latency":{"unit":"ms"}}).
+const AnnotationMetrics = "bottle.data.act3-ace.io/metrics"
+
+// recordedDetails returns the details recorded in the bottle annotation by metric name
+func recordedDetails(b v1.Bottle) (map[string]Details, error) {
+	value, ok := b.Annotations[AnnotationMetrics]
+	if !ok {
+		return nil, nil
+	}
+	var details map[string]Details
+	if err := json.Unmarshal([]byte(value), &details); err != nil {
+		return nil, fmt.Errorf("decoding annotation %s: %w", AnnotationMetrics, err)
+	}
+	return details, nil
+}
+
+// merge returns the details with the empty fields filled in from inferred
+func (d Details) merge(inferred Details) Details {
+	if d.Unit == "" {
+		d.Unit = inferred.Unit
+	}
+	if d.Direction == "" {
+		d.Direction = inferred.Direction
+	}
+	if d.Split == "" {
+		d.Split = inferred.Split
+	}
+	return d
+}
+
+// FromBottle returns the typed metrics of the bottle (in order).
+// The details recorded in AnnotationMetrics take precedence over the details inferred from the metric names.
+func FromBottle(b v1.Bottle) ([]Metric, error) {
+	details, err := recordedDetails(b)
+	if err != nil {
+		return nil, err
+	}
+	metrics := make([]Metric, 0, len(b.Metrics))
+	var errs []error
+	for _, bm := range b.Metrics {
+		m, err := FromV1(bm)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if d, ok := details[m.Name]; ok {
+			m.Details = d.merge(m.Details)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, errors.Join(errs...)
+}
+
+// Apply sets the metrics of the bottle to the v1 form of the typed metrics and records their details in
+// AnnotationMetrics (the annotation is removed when no metric has details).
+// The bottle is not changed if any metric is invalid.  The annotations are copied before they are changed.
+func Apply(b *v1.Bottle, metrics []Metric) error {
+	bottleMetrics := make([]v1.Metric, len(metrics))
+	details := map[string]Details{}
+	for i, m := range metrics {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("metric %q: %w", m.Name, err)
+		}
+		bottleMetrics[i] = m.ToV1()
+		if m.Details != (Details{}) {
+			details[m.Name] = m.Details
+		}
+	}
+
+	annotations := maps.Clone(b.Annotations)
+	if len(details) == 0 {
+		delete(annotations, AnnotationMetrics)
+	} else {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("encoding annotation %s: %w", AnnotationMetrics, err)
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[AnnotationMetrics] = string(data)
+	}
+	b.Metrics = bottleMetrics
+	b.Annotations = annotations
+	return nil
+}
+
+// Issues reports the problems with the typed metrics of the bottle: an annotation that cannot be decoded, details of
+// metrics that do not exist and invalid details.  The issues are warnings since the bottle is still a valid v1 bottle.
+func Issues(b v1.Bottle) val.IssueList {
+	annotationPath := field.NewPath("annotations").Key(AnnotationMetrics)
+	details, err := recordedDetails(b)
+	if err != nil {
+		return val.IssueList{val.NewWarning(annotationPath, val.CodeInvalidAnnotation, err.Error())}
+	}
+
+	report := &val.Report{}
+	names := make(map[string]bool, len(b.Metrics))
+	for _, m := range b.Metrics {
+		names[m.Name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(details)) {
+		if !names[name] {
+			report.Add(val.NewIssue(annotationPath.Key(name), val.CodeInvalidAnnotation,
+				fmt.Sprintf("there is no metric named %q", name)))
+			continue
+		}
+		report.AddError(annotationPath.Key(name), details[name].Validate())
+	}
+	for i := range report.Issues {
+		report.Issues[i].Severity = val.SeverityWarning
+	}
+	return report.Issues
+}
@@ -0,0 +1,103 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+	val "github.com/act3-ai/bottle-schema/pkg/validation"
+)
+
+func TestApplyAndFromBottle(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := []Metric{
+		{
+			Name:  "accuracy",
+			Value: 0.985,
+			Details: Details{
+				Unit:      "1",
+				Direction: HigherIsBetter,
+				Interval:  &Interval{Lower: 0.98, Upper: 0.99},
+				Dataset:   "mnist",
+				Split:     SplitTest,
+			},
+		},
+		{Name: "size", Value: 12},
+	}
+	b := v1.NewBottle()
+	b.Annotations = map[string]string{"foo": "bar"}
+	annotations := b.Annotations
+	assert.NoError(Apply(&b, metrics))
+	assert.Equal([]v1.Metric{{Name: "accuracy", Value: "0.985"}, {Name: "size", Value: "12"}}, b.Metrics)
+	assert.JSONEq(`{"accuracy":{"unit":"1","direction":"higherIsBetter","interval":{"lower":0.98,"upper":0.99},"dataset":"mnist","split":"test"}}`,
+		b.Annotations[AnnotationMetrics])
+	assert.NotContains(annotations, AnnotationMetrics, "the annotations are copied")
+	assert.NoError(b.Validate())
+	assert.Empty(Issues(b))
+
+	got, err := FromBottle(b)
+	assert.NoError(err)
+	assert.Equal(metrics, got)
+
+	// without details the annotation is removed (from a copy)
+	annotations = b.Annotations
+	assert.NoError(Apply(&b, []Metric{{Name: "size", Value: 12}}))
+	assert.Equal(map[string]string{"foo": "bar"}, b.Annotations)
+	assert.Contains(annotations, AnnotationMetrics, "the annotations are copied")
+
+	// the bottle is not changed when a metric is invalid
+	assert.NoError(Apply(&b, metrics))
+	before := *b.DeepCopy()
+	assert.ErrorContains(Apply(&b, []Metric{{Name: "loss", Value: 1}, {Name: "size", Value: 12, Details: Details{Unit: "furlong"}}}), `metric "size"`)
+	assert.Equal(before, b)
+}
+
+func TestFromBottle(t *testing.T) {
+	assert := assert.New(t)
+
+	b := v1.NewBottle()
+	b.Metrics = []v1.Metric{
+		{Name: "test loss", Value: "0.25"},
+		{Name: "latency", Value: "12.5"},
+	}
+	// the recorded details take precedence over the inferred ones
+	b.Annotations = map[string]string{AnnotationMetrics: `{"latency": {"unit": "ms"}, "test loss": {"split": "holdout"}}`}
+	metrics, err := FromBottle(b)
+	assert.NoError(err)
+	assert.Equal([]Metric{
+		{Name: "test loss", Value: 0.25, Details: Details{Direction: LowerIsBetter, Split: "holdout"}},
+		{Name: "latency", Value: 12.5, Details: Details{Unit: "ms", Direction: LowerIsBetter}},
+	}, metrics)
+
+	b.Metrics = append(b.Metrics, v1.Metric{Name: "bad", Value: "x"})
+	metrics, err = FromBottle(b)
+	assert.ErrorContains(err, `metric "bad"`)
+	assert.Len(metrics, 2)
+
+	b.Annotations[AnnotationMetrics] = "{"
+	_, err = FromBottle(b)
+	assert.ErrorContains(err, "decoding annotation")
+}
+
+func TestIssues(t *testing.T) {
+	assert := assert.New(t)
+
+	b := v1.NewBottle()
+	b.Metrics = []v1.Metric{{Name: "latency", Value: "12.5"}}
+	b.Annotations = map[string]string{AnnotationMetrics: `{"latency": {"unit": "furlong"}, "speed": {"unit": "m/s"}}`}
+	issues := Issues(b)
+	if assert.Len(issues, 2) {
+		assert.Equal("annotations[bottle.data.act3-ace.io/metrics][latency].unit", issues[0].Field)
+		assert.Equal(val.SeverityWarning, issues[0].Severity)
+		assert.Equal("annotations[bottle.data.act3-ace.io/metrics][speed]", issues[1].Field)
+		assert.Contains(issues[1].Message, `there is no metric named "speed"`)
+	}
+
+	b.Annotations[AnnotationMetrics] = "["
+	issues = Issues(b)
+	if assert.Len(issues, 1) {
+		assert.Equal(val.CodeInvalidAnnotation, issues[0].Code)
+	}
+}
@@ -0,0 +1,9 @@
+// Package metric provides typed bottle metrics so they can be compared across bottles.
+// A v1 metric only has a name, a description and a value stored as a string.  A typed Metric adds a numeric value, a
+// unit (a UCUM-like code from the unit registry), the direction in which the metric improves, an optional confidence
+// interval and the dataset and split it was measured on.
+//
+// The typed details are stored in the bottle annotation AnnotationMetrics so bottles remain valid v1 documents.
+// FromBottle converts the metrics of a bottle (inferring the details that are not recorded from the metric names) and
+// Apply records typed metrics in a bottle.
+package metric
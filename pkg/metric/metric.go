@@ -0,0 +1,245 @@
+package metric
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+)
+
+// Direction is the direction in which a metric improves
+type Direction string
+
+const (
+	// HigherIsBetter is used for metrics that improve as they increase (e.g., accuracy)
+	HigherIsBetter Direction = "higherIsBetter"
+
+	// LowerIsBetter is used for metrics that improve as they decrease (e.g., loss)
+	LowerIsBetter Direction = "lowerIsBetter"
+)
+
+// Common dataset splits.  Other splits are allowed.
+const (
+	SplitTrain      = "train"
+	SplitValidation = "validation"
+	SplitTest       = "test"
+)
+
+// Interval is a confidence interval of a metric
+type Interval struct {
+	// Lower bound of the interval
+	Lower float64 `json:"lower"`
+
+	// Upper bound of the interval
+	Upper float64 `json:"upper"`
+
+	// Level is the confidence level in (0, 1), e.g., 0.95 (optional)
+	Level float64 `json:"level,omitempty"`
+}
+
+// Details are the typed information about a metric that the v1 metric does not have
+type Details struct {
+	// Unit is the unit code of the value (see ParseUnit).  Empty is dimensionless.
+	Unit string `json:"unit,omitempty"`
+
+	// Direction in which the metric improves (empty if unknown)
+	Direction Direction `json:"direction,omitempty"`
+
+	// Interval is the confidence interval of the value (optional)
+	Interval *Interval `json:"interval,omitempty"`
+
+	// Dataset the metric was measured on (e.g., a bottle reference or a well known dataset name)
+	Dataset string `json:"dataset,omitempty"`
+
+	// Split of the dataset the metric was measured on (e.g., "test")
+	Split string `json:"split,omitempty"`
+}
+
+// Metric is a metric with a numeric value and its details
+type Metric struct {
+	// Name is the name of the metric
+	Name string `json:"name"`
+
+	// Description of what the metric represents
+	Description string `json:"description,omitempty"`
+
+	// Value of the metric in Unit
+	Value float64 `json:"value"`
+
+	Details
+}
+
+// Validate Interval using ozzo-validation
+func (i Interval) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Upper, validation.Min(i.Lower).Error("must not be less than the lower bound")),
+		validation.Field(&i.Level, validation.Min(0.0).Exclusive(), validation.Max(1.0).Exclusive()),
+	)
+}
+
+// fieldRules returns the validation rules of the details (shared with Metric, which embeds the details)
+func (d *Details) fieldRules() []*validation.FieldRules {
+	return []*validation.FieldRules{
+		validation.Field(&d.Unit, validation.By(func(value any) error {
+			_, err := ParseUnit(value.(string))
+			return err
+		})),
+		validation.Field(&d.Direction, validation.In(HigherIsBetter, LowerIsBetter)),
+		validation.Field(&d.Interval),
+	}
+}
+
+// Validate Details using ozzo-validation
+func (d Details) Validate() error {
+	return validation.ValidateStruct(&d, d.fieldRules()...)
+}
+
+// Validate Metric using ozzo-validation
+func (m Metric) Validate() error {
+	return validation.ValidateStruct(&m, append([]*validation.FieldRules{
+		validation.Field(&m.Name, validation.Required),
+		validation.Field(&m.Value, validation.By(func(any) error {
+			if math.IsNaN(m.Value) || math.IsInf(m.Value, 0) {
+				return errors.New("must be a finite number")
+			}
+			if m.Interval != nil && (m.Value < m.Interval.Lower || m.Value > m.Interval.Upper) {
+				return errors.New("must be within the interval")
+			}
+			return nil
+		})),
+	}, m.Details.fieldRules()...)...)
+}
+
+// In returns the metric with the value (and interval) converted to the unit
+func (m Metric) In(unit string) (Metric, error) {
+	value, err := Convert(m.Value, m.Unit, unit)
+	if err != nil {
+		return Metric{}, err
+	}
+	converted := m
+	converted.Value = value
+	converted.Unit = unit
+	if m.Interval != nil {
+		interval := *m.Interval
+		interval.Lower, _ = Convert(interval.Lower, m.Unit, unit)
+		interval.Upper, _ = Convert(interval.Upper, m.Unit, unit)
+		converted.Interval = &interval
+	}
+	return converted, nil
+}
+
+// Compare returns a positive number if m is better than other, a negative number if it is worse and 0 if they are
+// equal.  The value of other is converted to the unit of m.  The direction of m is used (other must agree if it has
+// one) and an error is returned if it is not known.
+func (m Metric) Compare(other Metric) (int, error) {
+	direction := m.Direction
+	switch {
+	case direction == "":
+		return 0, fmt.Errorf("direction of metric %q is unknown", m.Name)
+	case other.Direction != "" && other.Direction != direction:
+		return 0, fmt.Errorf("metrics %q (%s) and %q (%s) improve in different directions", m.Name, direction, other.Name, other.Direction)
+	}
+	o, err := other.In(m.Unit)
+	if err != nil {
+		return 0, err
+	}
+	c := 0
+	switch {
+	case m.Value > o.Value:
+		c = 1
+	case m.Value < o.Value:
+		c = -1
+	}
+	if direction == LowerIsBetter {
+		c = -c
+	}
+	return c, nil
+}
+
+// ToV1 returns the v1 form of the metric (the details are lost, see Apply to keep them)
+func (m Metric) ToV1() v1.Metric {
+	return v1.Metric{
+		Name:        m.Name,
+		Description: m.Description,
+		Value:       strconv.FormatFloat(m.Value, 'g', -1, 64),
+	}
+}
+
+// FromV1 converts the v1 metric to a typed metric.  The value is parsed and the details are inferred from the name
+// (see InferDetails).
+func FromV1(m v1.Metric) (Metric, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(m.Value), 64)
+	if err != nil {
+		return Metric{}, fmt.Errorf("metric %q: invalid value %q: %w", m.Name, m.Value, err)
+	}
+	return Metric{
+		Name:        m.Name,
+		Description: m.Description,
+		Value:       value,
+		Details:     InferDetails(m.Name),
+	}, nil
+}
+
+// higherIsBetter and lowerIsBetter are the words in metric names that indicate the direction
+var (
+	higherIsBetter = map[string]bool{
+		"accuracy": true, "acc": true, "precision": true, "recall": true, "f1": true, "auc": true, "auroc": true,
+		"map": true, "iou": true, "dice": true, "bleu": true, "rouge": true, "r2": true, "throughput": true,
+		"fps": true, "score": true, "sensitivity": true, "specificity": true,
+	}
+	lowerIsBetter = map[string]bool{
+		"loss": true, "error": true, "err": true, "mse": true, "rmse": true, "mae": true, "perplexity": true,
+		"ppl": true, "latency": true, "time": true, "duration": true, "wer": true, "cer": true, "fpr": true,
+		"fnr": true, "eer": true,
+	}
+
+	// splits maps the words in metric names to the split they indicate
+	splits = map[string]string{
+		"train": SplitTrain, "training": SplitTrain,
+		"val": SplitValidation, "valid": SplitValidation, "validation": SplitValidation, "dev": SplitValidation,
+		"test": SplitTest, "testing": SplitTest, "eval": SplitTest, "evaluation": SplitTest,
+	}
+)
+
+// InferDetails infers the details of a metric from its name.
+// A registered unit in parentheses or brackets at the end of the name is the unit (e.g., "latency (ms)"), and words
+// of the name indicate the direction (e.g., "accuracy" or "loss") and the split (e.g., "test" or "training").
+// Details that cannot be inferred are left empty.
+func InferDetails(name string) Details {
+	var d Details
+	trimmed := strings.TrimSpace(name)
+	if n := len(trimmed); n > 2 && (trimmed[n-1] == ')' || trimmed[n-1] == ']') {
+		open := strings.LastIndexAny(trimmed, "([")
+		if open >= 0 {
+			if u, err := ParseUnit(strings.TrimSpace(trimmed[open+1 : n-1])); err == nil {
+				d.Unit = u.Code
+				trimmed = trimmed[:open]
+			}
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(trimmed), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		switch {
+		case d.Direction == "" && higherIsBetter[w]:
+			d.Direction = HigherIsBetter
+		case d.Direction == "" && lowerIsBetter[w]:
+			d.Direction = LowerIsBetter
+		}
+		if s, ok := splits[w]; ok && d.Split == "" {
+			d.Split = s
+		}
+	}
+	if d.Direction == "" && strings.HasSuffix(d.Unit, "/s") {
+		// rates (e.g., "{sample}/s") are throughputs
+		d.Direction = HigherIsBetter
+	}
+	return d
+}
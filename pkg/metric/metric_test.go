@@ -0,0 +1,125 @@
+package metric
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v1 "github.com/act3-ai/bottle-schema/pkg/apis/data.act3-ace.io/v1"
+)
+
+func TestInferDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		want Details
+	}{
+		{"accuracy", Details{Direction: HigherIsBetter}},
+		{"training loss", Details{Direction: LowerIsBetter, Split: SplitTrain}},
+		{"Test F1", Details{Direction: HigherIsBetter, Split: SplitTest}},
+		{"val_mAP", Details{Direction: HigherIsBetter, Split: SplitValidation}},
+		{"epochs", Details{}},
+		{"latency (ms)", Details{Unit: "ms", Direction: LowerIsBetter}},
+		{"accuracy [%]", Details{Unit: "%", Direction: HigherIsBetter}},
+		{"inference rate ({image}/s)", Details{Unit: "{image}/s", Direction: HigherIsBetter}},
+		{"foo (bar)", Details{}},
+		{"AUC", Details{Direction: HigherIsBetter}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, InferDetails(tt.name))
+		})
+	}
+}
+
+func TestFromV1(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := FromV1(v1.Metric{Name: "test loss", Description: "cross entropy", Value: "3.141592654"})
+	assert.NoError(err)
+	assert.Equal(Metric{
+		Name:        "test loss",
+		Description: "cross entropy",
+		Value:       3.141592654,
+		Details:     Details{Direction: LowerIsBetter, Split: SplitTest},
+	}, m)
+	assert.Equal(v1.Metric{Name: "test loss", Description: "cross entropy", Value: "3.141592654"}, m.ToV1())
+
+	_, err = FromV1(v1.Metric{Name: "loss", Value: "high"})
+	assert.ErrorContains(err, `metric "loss": invalid value "high"`)
+}
+
+func TestMetric_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	m := Metric{
+		Name:  "accuracy",
+		Value: 0.9,
+		Details: Details{
+			Unit:      "1",
+			Direction: HigherIsBetter,
+			Interval:  &Interval{Lower: 0.88, Upper: 0.92, Level: 0.95},
+			Dataset:   "mnist",
+			Split:     SplitTest,
+		},
+	}
+	assert.NoError(m.Validate())
+
+	bad := m
+	bad.Name = ""
+	bad.Value = 0.5
+	bad.Unit = "furlong"
+	bad.Direction = "up"
+	bad.Interval = &Interval{Lower: 0.92, Upper: 0.88, Level: 95}
+	err := bad.Validate()
+	assert.ErrorContains(err, "name: cannot be blank")
+	assert.ErrorContains(err, "value: must be within the interval")
+	assert.ErrorContains(err, `unit: unknown unit "furlong"`)
+	assert.ErrorContains(err, "direction: must be a valid value")
+	assert.ErrorContains(err, "upper: must not be less than the lower bound")
+	assert.ErrorContains(err, "level: must be less than 1")
+
+	bad = m
+	bad.Value = math.NaN()
+	bad.Interval = nil
+	assert.ErrorContains(bad.Validate(), "value: must be a finite number")
+
+	// the details are flattened
+	data, err := json.Marshal(m)
+	assert.NoError(err)
+	assert.JSONEq(`{"name":"accuracy","value":0.9,"unit":"1","direction":"higherIsBetter",
+		"interval":{"lower":0.88,"upper":0.92,"level":0.95},"dataset":"mnist","split":"test"}`, string(data))
+}
+
+func TestMetric_Compare(t *testing.T) {
+	assert := assert.New(t)
+
+	fast := Metric{Name: "latency", Value: 900, Details: Details{Unit: "ms", Direction: LowerIsBetter}}
+	slow := Metric{Name: "latency", Value: 1.2, Details: Details{Unit: "s"}}
+
+	c, err := fast.Compare(slow)
+	assert.NoError(err)
+	assert.Positive(c)
+
+	slow.Direction = LowerIsBetter
+	c, err = slow.Compare(fast)
+	assert.NoError(err)
+	assert.Negative(c)
+
+	c, err = fast.Compare(Metric{Name: "latency", Value: 0.9, Details: Details{Unit: "s"}})
+	assert.NoError(err)
+	assert.Zero(c)
+
+	converted, err := slow.In("ms")
+	assert.NoError(err)
+	assert.InDelta(1200, converted.Value, 1e-9)
+	assert.Equal("ms", converted.Unit)
+
+	_, err = slow.Compare(Metric{Name: "latency", Value: 1, Details: Details{Direction: HigherIsBetter}})
+	assert.ErrorContains(err, "different directions")
+	_, err = Metric{Name: "size", Value: 1}.Compare(Metric{Name: "size", Value: 2})
+	assert.ErrorContains(err, "direction of metric \"size\" is unknown")
+	_, err = fast.Compare(Metric{Name: "latency", Value: 1, Details: Details{Unit: "By"}})
+	assert.ErrorContains(err, "cannot convert")
+}
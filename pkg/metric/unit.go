@@ -0,0 +1,181 @@
+package metric
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dimensions of the units in the registry.  Units can only be converted to units of the same dimension.
+const (
+	// Dimensionless is the dimension of ratios and counts (e.g., "1" and "%")
+	Dimensionless = "1"
+
+	// Time is the dimension of durations (base unit "s")
+	Time = "time"
+
+	// Information is the dimension of amounts of data (base unit "By")
+	Information = "information"
+
+	// Frequency is the dimension of rates (base unit "Hz")
+	Frequency = "frequency"
+
+	// Energy is the dimension of energy (base unit "J")
+	Energy = "energy"
+
+	// Power is the dimension of power (base unit "W")
+	Power = "power"
+
+	// Mass is the dimension of mass (base unit "g")
+	Mass = "mass"
+
+	// Length is the dimension of length (base unit "m")
+	Length = "length"
+)
+
+// ErrUnknownUnit is returned when a unit code is not in the registry
+var ErrUnknownUnit = errors.New("unknown unit")
+
+// Unit is a unit of measure identified by a UCUM-like code (e.g., "ms", "%", "By" or "{token}/s")
+type Unit struct {
+	// Code is the case-sensitive unit code
+	Code string
+
+	// Dimension of the unit.  Annotations (e.g., "{token}") are their own dimension so they are only comparable with
+	// the same annotation and compound units have compound dimensions (e.g., "{token}/time").
+	Dimension string
+
+	// Factor converts a value in this unit to the base unit of its dimension
+	Factor float64
+}
+
+var (
+	unitsMu sync.RWMutex
+
+	// units is the registry of units by code
+	units = map[string]Unit{}
+)
+
+func init() {
+	for _, u := range []Unit{
+		{"1", Dimensionless, 1},
+		{"%", Dimensionless, 1e-2},
+		{"[ppm]", Dimensionless, 1e-6},
+
+		{"ns", Time, 1e-9},
+		{"us", Time, 1e-6},
+		{"ms", Time, 1e-3},
+		{"s", Time, 1},
+		{"min", Time, 60},
+		{"h", Time, 3600},
+		{"d", Time, 86400},
+
+		{"bit", Information, 0.125},
+		{"By", Information, 1},
+		{"kBy", Information, 1e3},
+		{"MBy", Information, 1e6},
+		{"GBy", Information, 1e9},
+		{"TBy", Information, 1e12},
+		{"KiBy", Information, 1 << 10},
+		{"MiBy", Information, 1 << 20},
+		{"GiBy", Information, 1 << 30},
+		{"TiBy", Information, 1 << 40},
+
+		{"Hz", Frequency, 1},
+		{"kHz", Frequency, 1e3},
+		{"MHz", Frequency, 1e6},
+		{"GHz", Frequency, 1e9},
+
+		{"J", Energy, 1},
+		{"kJ", Energy, 1e3},
+		{"MJ", Energy, 1e6},
+		{"W.h", Energy, 3600},
+		{"kW.h", Energy, 3.6e6},
+
+		{"mW", Power, 1e-3},
+		{"W", Power, 1},
+		{"kW", Power, 1e3},
+
+		{"mg", Mass, 1e-3},
+		{"g", Mass, 1},
+		{"kg", Mass, 1e3},
+
+		{"mm", Length, 1e-3},
+		{"cm", Length, 1e-2},
+		{"m", Length, 1},
+		{"km", Length, 1e3},
+	} {
+		units[u.Code] = u
+	}
+}
+
+// RegisterUnit adds a unit to the registry.  It fails if the code is already registered or is not a simple code.
+func RegisterUnit(u Unit) error {
+	if u.Code == "" || strings.ContainsAny(u.Code, "/{} ") {
+		return fmt.Errorf("invalid unit code %q", u.Code)
+	}
+	if u.Dimension == "" || u.Factor <= 0 {
+		return fmt.Errorf("unit %q must have a dimension and a positive factor", u.Code)
+	}
+	unitsMu.Lock()
+	defer unitsMu.Unlock()
+	if _, ok := units[u.Code]; ok {
+		return fmt.Errorf("unit %q is already registered", u.Code)
+	}
+	units[u.Code] = u
+	return nil
+}
+
+// ParseUnit returns the unit with the code.
+// The code is a registered unit, an annotation in braces (e.g., "{token}") or the ratio of two of those (e.g., "By/s"
+// or "{sample}/s").  The empty code is the dimensionless unit "1".
+func ParseUnit(code string) (Unit, error) {
+	if code == "" {
+		return ParseUnit("1")
+	}
+	num, den, ratio := strings.Cut(code, "/")
+	n, err := simpleUnit(num)
+	if err != nil || !ratio {
+		return n, err
+	}
+	d, err := simpleUnit(den)
+	if err != nil {
+		return Unit{}, err
+	}
+	return Unit{Code: code, Dimension: n.Dimension + "/" + d.Dimension, Factor: n.Factor / d.Factor}, nil
+}
+
+// simpleUnit returns the registered unit or annotation with the code
+func simpleUnit(code string) (Unit, error) {
+	if strings.HasPrefix(code, "{") && strings.HasSuffix(code, "}") && len(code) > 2 &&
+		!strings.ContainsAny(code[1:len(code)-1], "{}/") {
+		return Unit{Code: code, Dimension: code, Factor: 1}, nil
+	}
+	unitsMu.RLock()
+	defer unitsMu.RUnlock()
+	u, ok := units[code]
+	if !ok {
+		return Unit{}, fmt.Errorf("%w %q", ErrUnknownUnit, code)
+	}
+	return u, nil
+}
+
+// Convert converts the value from one unit to another.  The units must have the same dimension.
+func Convert(value float64, from, to string) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+	f, err := ParseUnit(from)
+	if err != nil {
+		return 0, err
+	}
+	t, err := ParseUnit(to)
+	if err != nil {
+		return 0, err
+	}
+	if f.Dimension != t.Dimension {
+		return 0, fmt.Errorf("cannot convert %q (%s) to %q (%s)", f.Code, f.Dimension, t.Code, t.Dimension)
+	}
+	return value * f.Factor / t.Factor, nil
+}
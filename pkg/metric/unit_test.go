@@ -0,0 +1,73 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUnit(t *testing.T) {
+	assert := assert.New(t)
+
+	u, err := ParseUnit("ms")
+	assert.NoError(err)
+	assert.Equal(Unit{Code: "ms", Dimension: Time, Factor: 1e-3}, u)
+
+	u, err = ParseUnit("")
+	assert.NoError(err)
+	assert.Equal(Dimensionless, u.Dimension)
+
+	u, err = ParseUnit("{token}/s")
+	assert.NoError(err)
+	assert.Equal(Unit{Code: "{token}/s", Dimension: "{token}/" + Time, Factor: 1}, u)
+
+	u, err = ParseUnit("MBy/ms")
+	assert.NoError(err)
+	assert.Equal("information/time", u.Dimension)
+	assert.InDelta(1e9, u.Factor, 1e-3)
+
+	for _, code := range []string{"MS", "furlong", "{}", "s/", "a/b/c", "{a/b}"} {
+		_, err = ParseUnit(code)
+		assert.ErrorIs(err, ErrUnknownUnit, code)
+	}
+}
+
+func TestRegisterUnit(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(RegisterUnit(Unit{Code: "[test_wk]", Dimension: Time, Factor: 7 * 86400}))
+	v, err := Convert(2, "[test_wk]", "d")
+	assert.NoError(err)
+	assert.InDelta(14, v, 1e-9)
+
+	assert.ErrorContains(RegisterUnit(Unit{Code: "s", Dimension: Time, Factor: 1}), "already registered")
+	assert.ErrorContains(RegisterUnit(Unit{Code: "a/b", Dimension: Time, Factor: 1}), "invalid unit code")
+	assert.ErrorContains(RegisterUnit(Unit{Code: "[test_zero]", Dimension: Time}), "positive factor")
+}
+
+func TestConvert(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := Convert(1500, "ms", "s")
+	assert.NoError(err)
+	assert.InDelta(1.5, v, 1e-12)
+
+	v, err = Convert(0.25, "1", "%")
+	assert.NoError(err)
+	assert.InDelta(25, v, 1e-12)
+
+	v, err = Convert(1, "GiBy", "MiBy")
+	assert.NoError(err)
+	assert.InDelta(1024, v, 1e-12)
+
+	v, err = Convert(2, "{sample}/s", "{sample}/min")
+	assert.NoError(err)
+	assert.InDelta(120, v, 1e-12)
+
+	_, err = Convert(1, "ms", "By")
+	assert.ErrorContains(err, "cannot convert")
+	_, err = Convert(1, "{token}/s", "{sample}/s")
+	assert.ErrorContains(err, "cannot convert")
+	_, err = Convert(1, "ms", "parsec")
+	assert.ErrorIs(err, ErrUnknownUnit)
+}
@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/act3-ai/bottle-schema/pkg/archive"
+)
+
+// PartContentProvider provides the uncompressed size and content digest of a part.
+// Older bottle versions only recorded the layer (compressed) size and digest so the conversion chain calls this (when
+// provided in the Context) to fill in the values required by the current version.
+type PartContentProvider interface {
+	// PartContent returns the size and digest of the part's content.  For directory parts this is the size and digest
+	// of the (uncompressed) archive.  Return an error wrapping fs.ErrNotExist if the part is not available locally
+	// (e.g., a part selector was used when pulling) so the field is left to be recomputed later.
+	PartContent(name string) (int64, digest.Digest, error)
+}
+
+// PartContentFunc is an adapter to allow the use of an ordinary function as a PartContentProvider
+type PartContentFunc func(name string) (int64, digest.Digest, error)
+
+// PartContent calls f(name)
+func (f PartContentFunc) PartContent(name string) (int64, digest.Digest, error) {
+	return f(name)
+}
+
+// FSContentProvider is a PartContentProvider that computes the part content from the bottle's directory
+type FSContentProvider struct {
+	// FS is the bottle's directory (part names are relative to it)
+	FS fs.FS
+
+	// Archive writes the archive of the directory dir in FS to w (optional, defaults to archive.Tar).
+	// It is used to compute the size and digest of directory parts.
+	Archive func(fsys fs.FS, dir string, w io.Writer) error
+}
+
+// NewDirContentProvider returns a FSContentProvider for the bottle in the local directory dir
+func NewDirContentProvider(dir string) *FSContentProvider {
+	return &FSContentProvider{FS: os.DirFS(dir)}
+}
+
+// PartContent implements PartContentProvider
+func (p *FSContentProvider) PartContent(name string) (int64, digest.Digest, error) {
+	name = path.Clean(strings.TrimSuffix(name, "/"))
+	info, err := fs.Stat(p.FS, name)
+	if err != nil {
+		return 0, "", err
+	}
+
+	digester := digest.Canonical.Digester()
+	cw := &countingWriter{w: digester.Hash()}
+	if info.IsDir() {
+		archiveDir := p.Archive
+		if archiveDir == nil {
+			archiveDir = archive.Tar
+		}
+		if err := archiveDir(p.FS, name, cw); err != nil {
+			return 0, "", fmt.Errorf("archiving part %q: %w", name, err)
+		}
+	} else {
+		f, err := p.FS.Open(name)
+		if err != nil {
+			return 0, "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(cw, f); err != nil {
+			return 0, "", fmt.Errorf("reading part %q: %w", name, err)
+		}
+	}
+
+	return cw.n, digester.Digest(), nil
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
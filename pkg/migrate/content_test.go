@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/act3-ai/bottle-schema/pkg/archive"
+)
+
+func TestFSContentProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	p := &FSContentProvider{FS: fstest.MapFS{
+		"file.txt":     &fstest.MapFile{Data: []byte("hello")},
+		"dir/a/b.txt":  &fstest.MapFile{Data: []byte("b")},
+		"dir/a/c.json": &fstest.MapFile{Data: []byte("{}")},
+	}}
+
+	size, dgst, err := p.PartContent("file.txt")
+	assert.NoError(err)
+	assert.Equal(int64(5), size)
+	assert.Equal(digest.FromString("hello"), dgst)
+
+	_, _, err = p.PartContent("missing.txt")
+	assert.ErrorIs(err, fs.ErrNotExist)
+
+	// the default archive is reproducible
+	buf := &bytes.Buffer{}
+	assert.NoError(archive.Tar(p.FS, "dir", buf))
+	size, dgst, err = p.PartContent("dir/")
+	assert.NoError(err)
+	assert.Equal(int64(buf.Len()), size)
+	assert.Equal(digest.FromBytes(buf.Bytes()), dgst)
+
+	p.Archive = func(fsys fs.FS, dir string, w io.Writer) error {
+		_, err := io.WriteString(w, dir)
+		return err
+	}
+	size, dgst, err = p.PartContent("dir/")
+	assert.NoError(err)
+	assert.Equal(int64(3), size)
+	assert.Equal(digest.FromString("dir"), dgst)
+
+	p.Archive = func(fsys fs.FS, dir string, w io.Writer) error {
+		return errors.New("boom")
+	}
+	_, _, err = p.PartContent("dir")
+	assert.ErrorContains(err, "boom")
+}
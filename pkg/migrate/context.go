@@ -0,0 +1,51 @@
+package migrate
+
+import (
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+)
+
+// Context is passed as the context argument to runtime.Scheme.Convert to provide extra information to the conversion
+// functions and to collect the conversion report.  For backwards compatibility a *ocispec.Manifest may also be passed
+// directly as the context.
+type Context struct {
+	// Manifest is the manifest of the bottle being converted (optional).
+	// It is used to determine which parts are directories.
+	Manifest *ocispec.Manifest
+
+	// Report collects the lossy changes made by the conversion (optional)
+	Report *Report
+
+	// Content is used to recompute the part sizes and digests that older versions did not record (optional).
+	// Without it those fields are left empty and are reported as needing to be recomputed.
+	Content PartContentProvider
+}
+
+// FromScope extracts the manifest and report from the conversion scope.  Either (or both) may be nil.
+func FromScope(scope conversion.Scope) (*ocispec.Manifest, *Report) {
+	if scope == nil || scope.Meta() == nil {
+		return nil, nil
+	}
+	switch c := scope.Meta().Context.(type) {
+	case *ocispec.Manifest:
+		return c, nil
+	case *Context:
+		if c == nil {
+			return nil, nil
+		}
+		return c.Manifest, c.Report
+	default:
+		return nil, nil
+	}
+}
+
+// ContentFromScope extracts the part content provider from the conversion scope (nil if there is none)
+func ContentFromScope(scope conversion.Scope) PartContentProvider {
+	if scope == nil || scope.Meta() == nil {
+		return nil
+	}
+	if c, ok := scope.Meta().Context.(*Context); ok && c != nil {
+		return c.Content
+	}
+	return nil
+}
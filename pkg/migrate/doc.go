@@ -0,0 +1,4 @@
+// Package migrate provides the context that is passed to the bottle conversion functions (through
+// runtime.Scheme.Convert), the report of what a conversion could not carry over and the PartContentProvider used to
+// recompute the part sizes and digests that older versions did not record.
+package migrate
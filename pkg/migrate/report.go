@@ -0,0 +1,115 @@
+package migrate
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ChangeKind categorizes a lossy change made during conversion
+type ChangeKind string
+
+const (
+	// ChangeDropped indicates the field had a value that cannot be represented in the target version
+	ChangeDropped ChangeKind = "dropped"
+
+	// ChangeDefaulted indicates the field is not present in the source version so a default value was used
+	ChangeDefaulted ChangeKind = "defaulted"
+
+	// ChangeRecompute indicates the field could not be converted and must be recomputed from the bottle's content
+	// (e.g., the uncompressed size and content digest of a part)
+	ChangeRecompute ChangeKind = "recompute"
+)
+
+// Change is a single field that was not converted exactly
+type Change struct {
+	// Field is the path to the field (e.g., "parts[0].digest").
+	// Dropped fields use the path in the source version, otherwise the path is in the target version.
+	Field string `json:"field"`
+
+	// Kind of change
+	Kind ChangeKind `json:"kind"`
+
+	// From is the API version being converted from
+	From string `json:"from"`
+
+	// To is the API version being converted to
+	To string `json:"to"`
+
+	// Message is the human readable explanation
+	Message string `json:"message"`
+}
+
+// Report lists the changes that a conversion made that are not exact (i.e., that lose information)
+type Report struct {
+	// Changes in the order they were made
+	Changes []Change `json:"changes"`
+}
+
+// Add records a change.  It is safe to call on a nil Report (the change is discarded).
+func (r *Report) Add(fldPath *field.Path, kind ChangeKind, from, to, message string) {
+	if r == nil {
+		return
+	}
+	r.Changes = append(r.Changes, Change{
+		Field:   fldPath.String(),
+		Kind:    kind,
+		From:    from,
+		To:      to,
+		Message: message,
+	})
+}
+
+// Dropped records that the field's value could not be represented in the target version
+func (r *Report) Dropped(fldPath *field.Path, from, to, message string) {
+	r.Add(fldPath, ChangeDropped, from, to, message)
+}
+
+// IsLossless returns true if no changes were recorded
+func (r *Report) IsLossless() bool {
+	return r == nil || len(r.Changes) == 0
+}
+
+// Defaulted records that the field was set to a default value since the source version does not have it
+func (r *Report) Defaulted(fldPath *field.Path, from, to, message string) {
+	r.Add(fldPath, ChangeDefaulted, from, to, message)
+}
+
+// Recompute records that the field must be recomputed from the bottle's content
+func (r *Report) Recompute(fldPath *field.Path, from, to, message string) {
+	r.Add(fldPath, ChangeRecompute, from, to, message)
+}
+
+var indexRegexp = regexp.MustCompile(`^([A-Za-z]+)\[(\d+)\]`)
+
+// RecomputeIndices returns the (sorted, unique) indices of the elements of the list field (e.g., "parts" or
+// "publicArtifacts") that have at least one field that must be recomputed.
+func (r *Report) RecomputeIndices(list string) []int {
+	if r == nil {
+		return nil
+	}
+	var indices []int
+	seen := map[int]struct{}{}
+	for _, c := range r.Changes {
+		if c.Kind != ChangeRecompute {
+			continue
+		}
+		m := indexRegexp.FindStringSubmatch(c.Field)
+		if m == nil || m[1] != list {
+			continue
+		}
+		i, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
@@ -0,0 +1,32 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestReport_RecomputeIndices(t *testing.T) {
+	assert := assert.New(t)
+
+	var nilReport *Report
+	nilReport.Recompute(field.NewPath("parts").Index(0), "a", "b", "discarded")
+	assert.True(nilReport.IsLossless())
+	assert.Nil(nilReport.RecomputeIndices("parts"))
+
+	report := &Report{}
+	assert.True(report.IsLossless())
+	parts := field.NewPath("parts")
+	report.Recompute(parts.Index(3).Child("digest"), "a", "b", "")
+	report.Defaulted(parts.Index(2).Child("format"), "a", "b", "")
+	report.Recompute(parts.Index(1).Child("size"), "a", "b", "")
+	report.Recompute(parts.Index(1).Child("digest"), "a", "b", "")
+	report.Recompute(field.NewPath("publicArtifacts").Index(0).Child("digest"), "a", "b", "")
+	report.Dropped(field.NewPath("keywords"), "a", "b", "")
+
+	assert.False(report.IsLossless())
+	assert.Equal([]int{1, 3}, report.RecomputeIndices("parts"))
+	assert.Equal([]int{0}, report.RecomputeIndices("publicArtifacts"))
+	assert.Empty(report.RecomputeIndices("keywords"))
+}
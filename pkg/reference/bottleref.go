@@ -0,0 +1,296 @@
+package reference
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/selectors"
+)
+
+// ErrInvalidBottleRef is returned (wrapped) for all malformed BottleRefs
+var ErrInvalidBottleRef = errors.New("invalid bottle reference")
+
+// Form is the syntactic form of a BottleRef
+type Form int
+
+const (
+	// FormOCI is an OCI repository reference with a tag and/or a manifest digest (e.g., registry.example.com/repo/name:v1@sha256:...)
+	FormOCI Form = iota
+
+	// FormBottle is a URI with the "bottle" scheme (e.g., bottle:sha256:...)
+	FormBottle
+
+	// FormHash is a URI with the "hash" scheme from hash-uri (e.g., hash://sha256/...?type=application/vnd.act3-ace.bottle.config.v1+json)
+	FormHash
+)
+
+// String returns the name of the form
+func (f Form) String() string {
+	switch f {
+	case FormOCI:
+		return "oci"
+	case FormBottle:
+		return "bottle"
+	case FormHash:
+		return "hash"
+	default:
+		return fmt.Sprintf("Form(%d)", int(f))
+	}
+}
+
+// BottleRef references a bottle or a part of a bottle via the part selectors.
+// See conventions.md for the forms that are supported.
+type BottleRef struct {
+	// Form is the syntactic form the reference was written in
+	Form Form
+
+	// Registry is the registry host (and optional port) for FormOCI references
+	Registry string
+
+	// Repository is the repository path within the registry for FormOCI references
+	Repository string
+
+	// Tag is the optional tag for FormOCI references.
+	// If a ManifestDigest is also provided then the tag is ignored when resolving the reference.
+	Tag string
+
+	// ManifestDigest is the optional manifest digest for FormOCI references
+	ManifestDigest digest.Digest
+
+	// BottleID is the bottle ID (digest of the bottle config) for FormBottle and FormHash references
+	BottleID digest.Digest
+
+	// ConfigMediaType is the media type of the bottle config given by the "type" query parameter of FormHash
+	// references (the current or the legacy bottle config media type).  Empty means mediatype.MediaTypeBottleConfig.
+	ConfigMediaType string
+
+	// Selectors are the part selectors.  Nil selects every part.
+	Selectors selectors.LabelSelectorSet
+}
+
+// selectorQueryKey is the query parameter used for selectors in the URI forms
+const selectorQueryKey = "selector"
+
+var (
+	// the following follow the grammar of github.com/distribution/reference
+	domainRegexp     = regexp.MustCompile(`^(?:localhost|(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*)(?::[0-9]+)?$`)
+	pathComponentExp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*$`)
+	tagRegexp        = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+)
+
+// ParseBottleRef parses a BottleRef in any of the forms documented in conventions.md
+func ParseBottleRef(ref string) (BottleRef, error) {
+	if ref == "" {
+		return BottleRef{}, fmt.Errorf("%w: empty reference", ErrInvalidBottleRef)
+	}
+
+	var r BottleRef
+	var err error
+	switch {
+	case strings.HasPrefix(ref, "bottle:"):
+		r, err = parseBottleURI(ref)
+	case strings.HasPrefix(ref, "hash:"):
+		r, err = parseHashURI(ref)
+	default:
+		r, err = parseOCI(ref)
+	}
+	if err != nil {
+		return BottleRef{}, fmt.Errorf("%w %q: %w", ErrInvalidBottleRef, ref, err)
+	}
+	return r, nil
+}
+
+// parseBottleURI parses bottle:sha256:...?selector=...&selector=...
+func parseBottleURI(ref string) (BottleRef, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return BottleRef{}, err
+	}
+	if u.Opaque == "" {
+		return BottleRef{}, errors.New(`the "bottle" scheme must be followed by a bottle ID (e.g., bottle:sha256:...)`)
+	}
+	bottleID, err := digest.Parse(u.Opaque)
+	if err != nil {
+		return BottleRef{}, fmt.Errorf("invalid bottle ID %q: %w", u.Opaque, err)
+	}
+	if u.Fragment != "" {
+		return BottleRef{}, errors.New(`part selectors for the "bottle" scheme must be provided as "selector" query parameters`)
+	}
+	sels, err := parseSelectors(u.Query()[selectorQueryKey])
+	if err != nil {
+		return BottleRef{}, err
+	}
+	return BottleRef{
+		Form:      FormBottle,
+		BottleID:  bottleID,
+		Selectors: sels,
+	}, nil
+}
+
+// parseHashURI parses hash://sha256/...?type=application/vnd.act3-ace.bottle.config.v1+json&selector=...
+func parseHashURI(ref string) (BottleRef, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return BottleRef{}, err
+	}
+	if u.Host == "" {
+		return BottleRef{}, errors.New(`the "hash" scheme must be of the form hash://<algorithm>/<hex>`)
+	}
+	if u.Fragment != "" {
+		return BottleRef{}, errors.New(`part selectors for the "hash" scheme must be provided as "selector" query parameters`)
+	}
+	qs := u.Query()
+	typeValue := qs.Get("type")
+	if !mediatype.IsBottleConfig(typeValue) {
+		return BottleRef{}, fmt.Errorf("type %q is not a bottle config media type", typeValue)
+	}
+	dgstStr := u.Host + ":" + strings.TrimPrefix(u.Path, "/")
+	bottleID, err := digest.Parse(dgstStr)
+	if err != nil {
+		return BottleRef{}, fmt.Errorf("invalid bottle ID %q: %w", dgstStr, err)
+	}
+	sels, err := parseSelectors(qs[selectorQueryKey])
+	if err != nil {
+		return BottleRef{}, err
+	}
+	return BottleRef{
+		Form:            FormHash,
+		BottleID:        bottleID,
+		ConfigMediaType: typeValue,
+		Selectors:       sels,
+	}, nil
+}
+
+// parseOCI parses registry/repository[:tag][@digest][#selector|selector]
+func parseOCI(ref string) (BottleRef, error) {
+	r := BottleRef{Form: FormOCI}
+
+	name, fragment, hasFragment := strings.Cut(ref, "#")
+	if hasFragment {
+		if fragment == "" {
+			return BottleRef{}, errors.New(`empty part selector after "#"`)
+		}
+		sels, err := parseSelectors(strings.Split(fragment, selectors.Separator))
+		if err != nil {
+			return BottleRef{}, err
+		}
+		r.Selectors = sels
+	}
+
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		dgst, err := digest.Parse(name[i+1:])
+		if err != nil {
+			return BottleRef{}, fmt.Errorf("invalid manifest digest %q: %w", name[i+1:], err)
+		}
+		r.ManifestDigest = dgst
+		name = name[:i]
+	}
+
+	// a colon after the last slash separates the tag (a colon before it is the registry port)
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		r.Tag = name[i+1:]
+		name = name[:i]
+		if !tagRegexp.MatchString(r.Tag) {
+			return BottleRef{}, fmt.Errorf("invalid tag %q", r.Tag)
+		}
+	}
+
+	registry, repository, found := strings.Cut(name, "/")
+	if !found || !domainRegexp.MatchString(registry) || !(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		return BottleRef{}, fmt.Errorf("missing or invalid registry in %q (e.g., registry.example.com/repo/name:tag)", name)
+	}
+	r.Registry = registry
+
+	if repository == "" {
+		return BottleRef{}, errors.New("missing repository")
+	}
+	for _, component := range strings.Split(repository, "/") {
+		if !pathComponentExp.MatchString(component) {
+			return BottleRef{}, fmt.Errorf("invalid repository %q (must be lowercase alphanumeric components separated by \"/\")", repository)
+		}
+	}
+	r.Repository = repository
+
+	if r.Tag == "" && r.ManifestDigest == "" {
+		return BottleRef{}, errors.New("a tag or manifest digest is required")
+	}
+	return r, nil
+}
+
+// parseSelectors parses the selectors with useful errors
+func parseSelectors(sels []string) (selectors.LabelSelectorSet, error) {
+	for _, s := range sels {
+		if strings.TrimSpace(s) == "" {
+			// an empty selector selects every part so it is most likely a typo (e.g., a trailing "|")
+			return nil, errors.New("empty part selector")
+		}
+		if _, err := selectors.Parse([]string{s}); err != nil {
+			return nil, fmt.Errorf("invalid part selector %q: %w", s, err)
+		}
+	}
+	return selectors.Parse(sels)
+}
+
+// Name returns the fully qualified repository name (registry and repository) for FormOCI references
+func (r BottleRef) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the BottleRef in the same form it was parsed from.
+// Selectors are written in their normalized form.
+func (r BottleRef) String() string {
+	sels := make([]string, len(r.Selectors))
+	for i, s := range r.Selectors {
+		sels[i] = s.String()
+	}
+
+	switch r.Form {
+	case FormBottle:
+		s := "bottle:" + r.BottleID.String()
+		if len(sels) > 0 {
+			s += "?" + selectorQuery(sels)
+		}
+		return s
+	case FormHash:
+		configMediaType := r.ConfigMediaType
+		if configMediaType == "" {
+			configMediaType = mediatype.MediaTypeBottleConfig
+		}
+		s := "hash://" + r.BottleID.Algorithm().String() + "/" + r.BottleID.Encoded() +
+			"?type=" + url.QueryEscape(configMediaType)
+		if len(sels) > 0 {
+			s += "&" + selectorQuery(sels)
+		}
+		return s
+	default:
+		s := r.Name()
+		if r.Tag != "" {
+			s += ":" + r.Tag
+		}
+		if r.ManifestDigest != "" {
+			s += "@" + r.ManifestDigest.String()
+		}
+		if len(sels) > 0 {
+			s += "#" + strings.Join(sels, selectors.Separator)
+		}
+		return s
+	}
+}
+
+// selectorQuery encodes the selectors as query parameters
+func selectorQuery(sels []string) string {
+	parts := make([]string, len(sels))
+	for i, s := range sels {
+		parts[i] = selectorQueryKey + "=" + url.QueryEscape(s)
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,126 @@
+package reference
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	"github.com/act3-ai/bottle-schema/pkg/selectors"
+)
+
+func TestParseBottleRef(t *testing.T) {
+	manifestDigest := digest.Digest("sha256:05a8efd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9")
+	bottleID := digest.Digest("sha256:beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9")
+	sel, err := selectors.Parse([]string{"partkey!=value1,mykey=value2", "partkey2=45"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		ref  string
+		want BottleRef
+		// wantString is the expected output of String() (if different from ref)
+		wantString string
+	}{
+		{
+			"tag",
+			"registry.example.com/repo/name:v1",
+			BottleRef{Form: FormOCI, Registry: "registry.example.com", Repository: "repo/name", Tag: "v1"},
+			"",
+		},
+		{
+			"tag with selectors",
+			"registry.example.com/repo/name:v1#partkey!=value1,mykey=value2|partkey2=45",
+			BottleRef{Form: FormOCI, Registry: "registry.example.com", Repository: "repo/name", Tag: "v1", Selectors: sel},
+			"registry.example.com/repo/name:v1#mykey=value2,partkey!=value1|partkey2=45",
+		},
+		{
+			"digest",
+			"registry.example.com/repo/name@" + manifestDigest.String() + "#partkey2=45",
+			BottleRef{Form: FormOCI, Registry: "registry.example.com", Repository: "repo/name", ManifestDigest: manifestDigest, Selectors: sel[1:]},
+			"",
+		},
+		{
+			"tag and digest with port",
+			"localhost:5000/repo/name:v1@" + manifestDigest.String(),
+			BottleRef{Form: FormOCI, Registry: "localhost:5000", Repository: "repo/name", Tag: "v1", ManifestDigest: manifestDigest},
+			"",
+		},
+		{
+			"bottle scheme",
+			"bottle:" + bottleID.String(),
+			BottleRef{Form: FormBottle, BottleID: bottleID},
+			"",
+		},
+		{
+			"bottle scheme with selectors",
+			"bottle:" + bottleID.String() + "?selector=partkey!=value1,mykey=value2&selector=partkey2=45",
+			BottleRef{Form: FormBottle, BottleID: bottleID, Selectors: sel},
+			"bottle:" + bottleID.String() + "?selector=mykey%3Dvalue2%2Cpartkey%21%3Dvalue1&selector=partkey2%3D45",
+		},
+		{
+			"hash scheme with selectors",
+			"hash://sha256/beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?type=application/vnd.act3-ace.bottle.config.v1%2Bjson&selector=partkey!=value1,mykey=value2&selector=partkey2=45",
+			BottleRef{Form: FormHash, BottleID: bottleID, ConfigMediaType: mediatype.MediaTypeBottleConfig, Selectors: sel},
+			"hash://sha256/beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?type=application%2Fvnd.act3-ace.bottle.config.v1%2Bjson&selector=mykey%3Dvalue2%2Cpartkey%21%3Dvalue1&selector=partkey2%3D45",
+		},
+		{
+			"hash scheme with the legacy type",
+			"hash://sha256/beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?type=application%2Fvnd.act3-ace.dataset.config.v1%2Bjson",
+			BottleRef{Form: FormHash, BottleID: bottleID, ConfigMediaType: mediatype.MediaTypeBottleConfigLegacy},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBottleRef(tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			wantString := tt.wantString
+			if wantString == "" {
+				wantString = tt.ref
+			}
+			assert.Equal(t, wantString, got.String())
+
+			// round trip
+			again, err := ParseBottleRef(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
+
+func TestParseBottleRef_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		wantErr string
+	}{
+		{"empty", "", `invalid bottle reference: empty reference`},
+		{"no registry", "repo/name:v1", `invalid bottle reference "repo/name:v1": missing or invalid registry in "repo/name" (e.g., registry.example.com/repo/name:tag)`},
+		{"no repository", "registry.example.com:v1", `invalid bottle reference "registry.example.com:v1": missing or invalid registry in "registry.example.com" (e.g., registry.example.com/repo/name:tag)`},
+		{"uppercase repository", "registry.example.com/Repo:v1", `invalid bottle reference "registry.example.com/Repo:v1": invalid repository "Repo" (must be lowercase alphanumeric components separated by "/")`},
+		{"no tag or digest", "registry.example.com/repo", `invalid bottle reference "registry.example.com/repo": a tag or manifest digest is required`},
+		{"bad tag", "registry.example.com/repo:-v1", `invalid bottle reference "registry.example.com/repo:-v1": invalid tag "-v1"`},
+		{"bad manifest digest", "registry.example.com/repo@sha256:deedbeef", `invalid bottle reference "registry.example.com/repo@sha256:deedbeef": invalid manifest digest "sha256:deedbeef": invalid checksum digest length`},
+		{"empty selector", "registry.example.com/repo:v1#", `invalid bottle reference "registry.example.com/repo:v1#": empty part selector after "#"`},
+		{"trailing selector separator", "registry.example.com/repo:v1#a=b|", `invalid bottle reference "registry.example.com/repo:v1#a=b|": empty part selector`},
+		{"empty selector between", "registry.example.com/repo:v1#a=b||c=d", `invalid bottle reference "registry.example.com/repo:v1#a=b||c=d": empty part selector`},
+		{"bottle empty selector", "bottle:sha256:beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?selector=", `invalid bottle reference "bottle:sha256:beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?selector=": empty part selector`},
+		{"bad selector", "registry.example.com/repo:v1#a=b|=c", `invalid bottle reference "registry.example.com/repo:v1#a=b|=c": invalid part selector "=c": found '=', expected: !, identifier, or 'end of string'`},
+		{"bottle bad digest", "bottle:sha256:deedbeef", `invalid bottle reference "bottle:sha256:deedbeef": invalid bottle ID "sha256:deedbeef": invalid checksum digest length`},
+		{"bottle fragment", "bottle:sha256:beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9#a=b", `invalid bottle reference "bottle:sha256:beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9#a=b": part selectors for the "bottle" scheme must be provided as "selector" query parameters`},
+		{"hash not a bottle", "hash://sha256/beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?type=text/plain", `invalid bottle reference "hash://sha256/beefefd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?type=text/plain": type "text/plain" is not a bottle config media type`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBottleRef(tt.ref)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.True(t, errors.Is(err, ErrInvalidBottleRef))
+		})
+	}
+}
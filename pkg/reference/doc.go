@@ -0,0 +1,2 @@
+// Package reference provides parsing and formatting of references to bottles (BottleRefs)
+package reference
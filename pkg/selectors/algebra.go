@@ -0,0 +1,318 @@
+package selectors
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// constraint is the normalized form of the requirements of a selector on a single label key.
+// A label value is allowed if it is in "in" (when restricted), not in "notIn" and (when bounded) an integer strictly
+// between gt and lt.
+type constraint struct {
+	// exists is true if the label must exist
+	exists bool
+
+	// notExists is true if the label must not exist
+	notExists bool
+
+	// in is the set of allowed values (nil allows any value)
+	in map[string]bool
+
+	// notIn is the set of excluded values
+	notIn map[string]bool
+
+	// bounded is true if the value must be an integer in the range (gt, lt)
+	bounded bool
+	gt, lt  int64
+}
+
+// unconstrained is the constraint of a label key that is not in a selector
+var unconstrained = constraint{gt: math.MinInt64, lt: math.MaxInt64}
+
+// constraints returns the normalized constraints of the selector by label key.
+// It returns false if the selector can never match (i.e., labels.Nothing).
+func constraints(sel labels.Selector) (map[string]*constraint, bool) {
+	reqs, selectable := sel.Requirements()
+	if !selectable {
+		return nil, false
+	}
+	cs := map[string]*constraint{}
+	for _, r := range reqs {
+		c, ok := cs[r.Key()]
+		if !ok {
+			c = &constraint{gt: math.MinInt64, lt: math.MaxInt64}
+			cs[r.Key()] = c
+		}
+		values := r.ValuesUnsorted()
+		switch r.Operator() {
+		case selection.In, selection.Equals, selection.DoubleEquals:
+			c.exists = true
+			allowed := map[string]bool{}
+			for _, v := range values {
+				if c.in == nil || c.in[v] {
+					allowed[v] = true
+				}
+			}
+			c.in = allowed
+		case selection.NotIn, selection.NotEquals:
+			if c.notIn == nil {
+				c.notIn = map[string]bool{}
+			}
+			for _, v := range values {
+				c.notIn[v] = true
+			}
+		case selection.Exists:
+			c.exists = true
+		case selection.DoesNotExist:
+			c.notExists = true
+		case selection.GreaterThan, selection.LessThan:
+			c.exists = true
+			c.bounded = true
+			for _, v := range values {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					// the requirement cannot match anything
+					return nil, false
+				}
+				if r.Operator() == selection.GreaterThan {
+					c.gt = max(c.gt, n)
+				} else {
+					c.lt = min(c.lt, n)
+				}
+			}
+		}
+	}
+	return cs, true
+}
+
+// allowsAbsent returns true if the constraint allows the label to be missing
+func (c *constraint) allowsAbsent() bool {
+	return !c.exists && !c.bounded && c.in == nil
+}
+
+// allowsValue returns true if the constraint allows the label to have the value v
+func (c *constraint) allowsValue(v string) bool {
+	if c.notExists || c.notIn[v] || c.in != nil && !c.in[v] {
+		return false
+	}
+	if c.bounded {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= c.gt || n >= c.lt {
+			return false
+		}
+	}
+	return true
+}
+
+// satisfiable returns true if some label set (missing the label or with some value) satisfies the constraint
+func (c *constraint) satisfiable() bool {
+	if c.notExists {
+		return c.allowsAbsent()
+	}
+	if c.allowsAbsent() {
+		return true
+	}
+	if c.in != nil {
+		for v := range c.in {
+			if c.allowsValue(v) {
+				return true
+			}
+		}
+		return false
+	}
+	// there are many strings for each integer (e.g., "7" and "07") so notIn cannot exclude an integer
+	// the bounds may be the extremes of int64 so the difference is computed without overflow
+	return !c.bounded || c.gt < c.lt && uint64(c.lt)-uint64(c.gt) > 1
+}
+
+// implies returns true if every label value (or missing label) allowed by c is also allowed by other.
+// c must be satisfiable.
+func (c *constraint) implies(other *constraint) bool {
+	if c.allowsAbsent() && !other.allowsAbsent() {
+		return false
+	}
+	if c.notExists {
+		// only the missing label is allowed
+		return true
+	}
+	if c.in != nil {
+		// finitely many values
+		for v := range c.in {
+			if c.allowsValue(v) && !other.allowsValue(v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// infinitely many values
+	if other.notExists || other.in != nil {
+		return false
+	}
+	for v := range other.notIn {
+		if c.allowsValue(v) {
+			return false
+		}
+	}
+	if other.bounded {
+		return c.bounded && c.gt >= other.gt && c.lt <= other.lt
+	}
+	return true
+}
+
+// IsSatisfiable returns false if the selector cannot match any set of labels (e.g., "a=x,a=y" or "a,!a")
+func IsSatisfiable(sel labels.Selector) bool {
+	cs, ok := constraints(sel)
+	if !ok {
+		return false
+	}
+	for _, c := range cs {
+		if !c.satisfiable() {
+			return false
+		}
+	}
+	return true
+}
+
+// selectorSubsumes returns true if every set of labels matched by b is also matched by a
+func selectorSubsumes(a, b labels.Selector) bool {
+	bcs, ok := constraints(b)
+	if !ok {
+		return true
+	}
+	for _, c := range bcs {
+		if !c.satisfiable() {
+			// b matches nothing
+			return true
+		}
+	}
+	acs, ok := constraints(a)
+	if !ok {
+		return false
+	}
+	for key, ac := range acs {
+		bc, ok := bcs[key]
+		if !ok {
+			bc = &unconstrained
+		}
+		if !bc.implies(ac) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNothing returns true if the selector set cannot match any set of labels
+func (lss LabelSelectorSet) IsNothing() bool {
+	if lss == nil {
+		return false
+	}
+	return !slices.ContainsFunc(lss, IsSatisfiable)
+}
+
+// Subsumes returns true if every set of labels matched by other is also matched by lss.
+// This is conservative: each selector of other must be subsumed by a single selector of lss so it may return false
+// when other is only covered by several selectors of lss together.  For example, a cache of parts pulled with lss can
+// satisfy a request for other when this returns true.
+func (lss LabelSelectorSet) Subsumes(other LabelSelectorSet) bool {
+	if lss == nil {
+		return true
+	}
+	if other == nil {
+		return slices.ContainsFunc(lss, func(s labels.Selector) bool {
+			return s.Empty()
+		})
+	}
+	for _, b := range other {
+		if !slices.ContainsFunc(lss, func(a labels.Selector) bool {
+			return selectorSubsumes(a, b)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns the (simplified) selector set matching the labels matched by lss or other
+func (lss LabelSelectorSet) Union(other LabelSelectorSet) LabelSelectorSet {
+	if lss == nil || other == nil {
+		return Everything()
+	}
+	return slices.Concat(lss, other).Simplify()
+}
+
+// Intersection returns the (simplified) selector set matching the labels matched by both lss and other
+func (lss LabelSelectorSet) Intersection(other LabelSelectorSet) LabelSelectorSet {
+	switch {
+	case lss == nil:
+		return other.Simplify()
+	case other == nil:
+		return lss.Simplify()
+	}
+	result := LabelSelectorSet{}
+	for _, a := range lss {
+		for _, b := range other {
+			reqs, ok := a.Requirements()
+			if !ok {
+				continue
+			}
+			breqs, ok := b.Requirements()
+			if !ok {
+				continue
+			}
+			result = append(result, labels.NewSelector().Add(slices.Concat(reqs, breqs)...))
+		}
+	}
+	return result.Simplify()
+}
+
+// Simplify returns an equivalent selector set without redundant selectors.
+// Unsatisfiable selectors and selectors subsumed by another selector are removed and the rest are sorted.
+// A set with a selector that matches everything is Everything and a set that matches nothing is Nothing.
+func (lss LabelSelectorSet) Simplify() LabelSelectorSet {
+	if lss == nil {
+		return Everything()
+	}
+	var sels LabelSelectorSet
+	for _, s := range lss {
+		if s == nil || !IsSatisfiable(s) {
+			continue
+		}
+		if s.Empty() {
+			return Everything()
+		}
+		sels = append(sels, s)
+	}
+	slices.SortStableFunc(sels, func(a, b labels.Selector) int {
+		switch as, bs := a.String(), b.String(); {
+		case as < bs:
+			return -1
+		case as > bs:
+			return 1
+		}
+		return 0
+	})
+
+	result := LabelSelectorSet{}
+	for i, s := range sels {
+		redundant := false
+		for j, t := range sels {
+			if i == j || !selectorSubsumes(t, s) {
+				continue
+			}
+			// keep the first of equivalent selectors
+			if j < i || !selectorSubsumes(s, t) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package selectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// mustParse parses the string form of a selector set
+func mustParse(t *testing.T, s string) LabelSelectorSet {
+	t.Helper()
+	lss, err := ParseString(s)
+	require.NoError(t, err)
+	return lss
+}
+
+func TestIsSatisfiable(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"a=x", true},
+		{"a=x,a=y", false},
+		{"a in (x,y),a notin (x)", true},
+		{"a in (x,y),a notin (x,y)", false},
+		{"a,!a", false},
+		{"a!=x,!a", true},
+		{"a=x,!a", false},
+		{"a>5,a<7", true},
+		{"a>5,a<6", false},
+		{"a>5,a=6", true},
+		{"a>5,a in (3,4)", false},
+		{"a>5,a=x", false},
+		{"a=x,b=y,b!=y", false},
+		{"a<5", true},
+		{"a>5", true},
+		{"b=1,a<100", true},
+		{"a>9223372036854775806", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := labels.Parse(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, IsSatisfiable(sel))
+		})
+	}
+	assert.False(t, IsSatisfiable(labels.Nothing()))
+
+	assert.True(t, Nothing().IsNothing())
+	assert.False(t, Everything().IsNothing())
+	assert.True(t, mustParse(t, "a=x,a=y|b,!b").IsNothing())
+	assert.False(t, mustParse(t, "a=x,a=y|b").IsNothing())
+}
+
+func TestLabelSelectorSet_Subsumes(t *testing.T) {
+	tests := []struct {
+		name  string
+		lss   string
+		other string
+		want  bool
+	}{
+		{"same", "a=x", "a=x", true},
+		{"narrower", "a", "a=x,b=y", true},
+		{"wider", "a=x,b=y", "a", false},
+		{"in", "a in (x,y,z)", "a in (x,y)", true},
+		{"not in", "a in (x,y)", "a in (x,y,z)", false},
+		{"notin", "a!=x", "a=y", true},
+		{"notin other key", "a!=x", "b=y", false},
+		{"notin missing", "a!=x", "!a", true},
+		{"notin wider", "a notin (x)", "a notin (x,y)", true},
+		{"notin narrower", "a notin (x,y)", "a notin (x)", false},
+		{"does not exist", "!a", "!a,b", true},
+		{"exists vs missing", "a", "!a", false},
+		{"range", "a>1,a<10", "a>2,a<5", true},
+		{"range wider", "a>2,a<5", "a>1,a<10", false},
+		{"range value", "a>1", "a in (2,3)", true},
+		{"range not number", "a>1", "a=x", false},
+		{"less than", "a<10", "a<5", true},
+		{"less than wider", "a<5", "a<10", false},
+		{"greater than", "a>1", "a>5", true},
+		{"greater than value", "a>1", "a=7", true},
+		{"less than other key", "b=1", "b=1,a<100", true},
+		{"unsatisfiable other", "a=x", "b=y,b=z", true},
+		{"any selector", "a=x|b=y", "b=y,c=z", true},
+		{"each selector", "a=x|b=y", "a=x|b=y,c|c=z", false},
+		{"everything", "", "a=x", true},
+		{"everything other", "a=x", "", false},
+		{"nothing other", "a=x", NothingString, true},
+		{"nothing", NothingString, "a=x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mustParse(t, tt.lss).Subsumes(mustParse(t, tt.other)))
+		})
+	}
+
+	// a set with a selector that matches everything (only possible with Parse)
+	everything, err := Parse([]string{"a=x", ""})
+	require.NoError(t, err)
+	assert.True(t, everything.Subsumes(Everything()))
+}
+
+func TestLabelSelectorSet_Simplify(t *testing.T) {
+	tests := []struct {
+		name string
+		lss  string
+		want string
+	}{
+		{"everything", "", ""},
+		{"nothing", NothingString, NothingString},
+		{"unsatisfiable", "a=x,a=y", NothingString},
+		{"subsumed", "a=x,b=y|a|c=z", "a|c=z"},
+		{"duplicates", "b=y|a=x|b=y", "a=x|b=y"},
+		{"less than", "a<5", "a<5"},
+		{"greater than", "a>5|a>7", "a>5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustParse(t, tt.lss).Simplify()
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, mustParse(t, tt.want), got)
+		})
+	}
+
+	// a set with a selector that matches everything (only possible with Parse)
+	everything, err := Parse([]string{"a=x", ""})
+	require.NoError(t, err)
+	assert.Equal(t, Everything(), everything.Simplify())
+}
+
+func TestLabelSelectorSet_UnionIntersection(t *testing.T) {
+	assert := assert.New(t)
+
+	train := mustParse(t, "subset=train")
+	test := mustParse(t, "subset=test")
+	small := mustParse(t, "size=small")
+
+	assert.Equal("subset=test|subset=train", train.Union(test).String())
+	assert.Equal("subset=train", train.Union(mustParse(t, "subset=train,size=small")).String())
+	assert.Nil(train.Union(Everything()))
+	assert.Equal(train, train.Union(Nothing()))
+
+	assert.Equal(NothingString, train.Intersection(test).String())
+	assert.Equal("size=small,subset=train", train.Intersection(small).String())
+	assert.Equal("size=small,subset=test|size=small,subset=train", train.Union(test).Intersection(small).String())
+	assert.Equal("a<5,subset=train", train.Intersection(mustParse(t, "a<5")).String())
+	assert.Equal(mustParse(t, "a<10"), mustParse(t, "a<5").Union(mustParse(t, "a<10")))
+	assert.Equal(train, train.Intersection(Everything()))
+	assert.Equal(Nothing(), train.Intersection(Nothing()))
+	assert.Nil(Everything().Intersection(Everything()))
+
+	// the intersection is subsumed by both and the union subsumes both
+	both := train.Union(test).Intersection(small)
+	assert.True(train.Union(test).Subsumes(both))
+	assert.True(small.Subsumes(both))
+	assert.True(train.Union(test).Subsumes(train))
+
+	// matching agrees
+	lbls := labels.Set{"subset": "train", "size": "small"}
+	assert.True(both.Matches(lbls))
+	assert.False(both.Matches(labels.Set{"subset": "train"}))
+}
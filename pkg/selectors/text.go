@@ -0,0 +1,77 @@
+package selectors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// Separator separates the selectors in the string form of a LabelSelectorSet (e.g., "a=b,c!=d|e in (f, g)")
+	Separator = "|"
+
+	// NothingString is the string form of Nothing.  It is not a valid label selector.
+	// The string form of Everything is the empty string.
+	NothingString = "<nothing>"
+)
+
+// ParseString parses the string form of a LabelSelectorSet (the selectors separated by "|").
+// The empty string is Everything and NothingString is Nothing.  Empty selectors (e.g., "a=b|") are an error.
+func ParseString(s string) (LabelSelectorSet, error) {
+	switch strings.TrimSpace(s) {
+	case "":
+		return Everything(), nil
+	case NothingString:
+		return Nothing(), nil
+	}
+	sels := strings.Split(s, Separator)
+	for i, sel := range sels {
+		if strings.TrimSpace(sel) == "" {
+			// most likely a typo, an empty selector would select everything
+			return nil, fmt.Errorf("empty selector (at index %d) in %q", i, s)
+		}
+		if _, err := Parse([]string{sel}); err != nil {
+			return nil, fmt.Errorf("invalid selector %q (at index %d): %w", sel, i, err)
+		}
+	}
+	return Parse(sels)
+}
+
+// String returns the canonical string form of the selector set.
+// The selectors are normalized, sorted and deduplicated and then separated by "|".
+// Everything (or any set containing a selector that matches everything) is the empty string and Nothing is
+// NothingString.
+func (lss LabelSelectorSet) String() string {
+	if lss == nil {
+		return ""
+	}
+	if len(lss) == 0 {
+		return NothingString
+	}
+	sels := make([]string, 0, len(lss))
+	for _, s := range lss {
+		if s == nil || s.Empty() {
+			// matches everything
+			return ""
+		}
+		sels = append(sels, s.String())
+	}
+	slices.Sort(sels)
+	return strings.Join(slices.Compact(sels), Separator)
+}
+
+// MarshalText implements encoding.TextMarshaler (this is also used for JSON and YAML).
+// Do not use "omitempty" on LabelSelectorSet fields since Nothing would be omitted (and then decoded as Everything).
+func (lss LabelSelectorSet) MarshalText() ([]byte, error) {
+	return []byte(lss.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler (this is also used for JSON and YAML)
+func (lss *LabelSelectorSet) UnmarshalText(text []byte) error {
+	parsed, err := ParseString(string(text))
+	if err != nil {
+		return err
+	}
+	*lss = parsed
+	return nil
+}
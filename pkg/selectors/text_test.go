@@ -0,0 +1,97 @@
+package selectors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		str     string
+		wantErr string
+	}{
+		{"everything", "", nil, "", ""},
+		{"nothing", NothingString, []string{}, NothingString, ""},
+		{"single", "a=b", []string{"a=b"}, "a=b", ""},
+		{"multi", "x|a=b,c notin (front, back)", []string{"x", "a=b,c notin (front, back)"}, "a=b,c notin (back,front)|x", ""},
+		{"duplicates", "b=1,a=2|a=2,b=1", []string{"b=1,a=2", "a=2,b=1"}, "a=2,b=1", ""},
+		{"trailing separator", "a=b|", nil, "", `empty selector (at index 1) in "a=b|"`},
+		{"empty selector", "a=b||c=d", nil, "", `empty selector (at index 1) in "a=b||c=d"`},
+		{"blank selector", "a=b| ", nil, "", `empty selector (at index 1) in "a=b| "`},
+		{"invalid", "a=b|c in (", nil, "", `invalid selector "c in (" (at index 1)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseString(tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			want, err := Parse(tt.want)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+			assert.Equal(t, tt.str, got.String())
+
+			// the canonical form round trips
+			again, err := ParseString(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got.String(), again.String())
+		})
+	}
+}
+
+func TestLabelSelectorSet_Marshal(t *testing.T) {
+	type config struct {
+		Parts LabelSelectorSet `json:"parts" yaml:"parts"`
+	}
+	sel, err := ParseString("a=b|c")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		lss  LabelSelectorSet
+		json string
+		yaml string
+	}{
+		{"everything", Everything(), `{"parts":""}`, "parts: \"\"\n"},
+		{"nothing", Nothing(), `{"parts":"<nothing>"}`, "parts: <nothing>\n"},
+		{"selectors", sel, `{"parts":"a=b|c"}`, "parts: a=b|c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(config{tt.lss})
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.json, string(data))
+			var got config
+			require.NoError(t, json.Unmarshal(data, &got))
+			assert.Equal(t, tt.lss, got.Parts)
+
+			data, err = yaml.Marshal(config{tt.lss})
+			require.NoError(t, err)
+			assert.Equal(t, tt.yaml, string(data))
+			got = config{}
+			require.NoError(t, yaml.Unmarshal(data, &got))
+			assert.Equal(t, tt.lss, got.Parts)
+
+			got = config{}
+			require.NoError(t, sigsyaml.Unmarshal(data, &got))
+			assert.Equal(t, tt.lss, got.Parts)
+		})
+	}
+
+	// missing is everything
+	var got config
+	require.NoError(t, json.Unmarshal([]byte(`{}`), &got))
+	assert.Nil(t, got.Parts)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"parts":"a in ("}`), &got))
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	type inner struct {
+		Zeta  string `json:"zeta"`
+		Alpha int64  `json:"alpha"`
+	}
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"struct keys sorted", inner{"z", 1}, `{"alpha":1,"zeta":"z"}`},
+		{"map keys sorted", map[string]any{"b": []int{3, 1}, "a": nil, "c": true}, `{"a":null,"b":[3,1],"c":true}`},
+		{"no html escaping", map[string]string{"k": "<a&b>"}, `{"k":"<a&b>"}`},
+		{"unicode and escapes", "line\n\"quoted\" ü", `"line\n\"quoted\" ü"`},
+		{"large numbers preserved", map[string]int64{"n": 9007199254740993}, `{"n":9007199254740993}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(tt.v)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
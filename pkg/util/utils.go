@@ -23,6 +23,7 @@ func IsPathPrefix(path, prefixPath string) bool {
 }
 
 // ParseSourceURI will parse a URI for a source.  If it contains a bottle reference it will extract it along with any part selectors.
+// Only the "bottle" and "hash" schemes are recognized; use reference.ParseBottleRef for every form of bottle reference.
 func ParseSourceURI(uri string) (digest.Digest, selectors.LabelSelectorSet, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
@@ -57,6 +57,20 @@ func TestParseSourceURI(t *testing.T) {
 			sel,
 			nil,
 		},
+		{
+			"legacy bottle hash is not a bottle reference",
+			args{"hash://sha256/05a8efd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9?type=application/vnd.act3-ace.dataset.config.v1%2Bjson"},
+			"",
+			nil,
+			nil,
+		},
+		{
+			"bottle scheme fragment ignored",
+			args{"bottle:sha256:05a8efd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9#partkey2=45"},
+			digest.Digest("sha256:05a8efd3483c60a4364d3f6f328ee1897facdbffb043b51941424a34121bbbe9"),
+			selectors.Everything(),
+			nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// ArtifactSizeFunc returns the size of the public artifact with the given path (relative to the bottle).
+// Return an error wrapping fs.ErrNotExist if the artifact is not available (e.g., its part was not pulled) so the
+// size is not checked.
+type ArtifactSizeFunc func(path string) (int64, error)
+
+// artifactSizeKey is how we find the ArtifactSizeFunc in a context.Context.
+type artifactSizeKey struct{}
+
+// ContextWithArtifactSize adds the provider of public artifact sizes to the context.  This enables the size limit
+// checks on public artifacts (the bottle does not record their sizes).
+func ContextWithArtifactSize(ctx context.Context, size ArtifactSizeFunc) context.Context {
+	return context.WithValue(ctx, artifactSizeKey{}, size)
+}
+
+// ArtifactSizeFromContext returns the provider of public artifact sizes (or nil if the size checks are disabled)
+func ArtifactSizeFromContext(ctx context.Context) ArtifactSizeFunc {
+	size, _ := ctx.Value(artifactSizeKey{}).(ArtifactSizeFunc)
+	return size
+}
+
+// ArtifactSizeFromFS returns an ArtifactSizeFunc for the bottle's directory fsys
+func ArtifactSizeFromFS(fsys fs.FS) ArtifactSizeFunc {
+	return func(p string) (int64, error) {
+		info, err := fs.Stat(fsys, path.Clean(p))
+		if err != nil {
+			return 0, err
+		}
+		if !info.Mode().IsRegular() {
+			return 0, fmt.Errorf("%q is not a regular file", p)
+		}
+		return info.Size(), nil
+	}
+}
@@ -27,6 +27,23 @@ func ContextWithManifest(ctx context.Context, manifest *ocispec.Manifest) contex
 	return context.WithValue(ctx, manifestKey{}, manifest)
 }
 
+// layerConsistencyKey is how we find if the layer consistency checks are enabled in a context.Context.
+type layerConsistencyKey struct{}
+
+// ContextWithLayerConsistency enables the full consistency checks between the bottle and the manifest in the context.
+// Besides the number of layers and the trailing slashes, the digest and size of uncompressed layers must equal those
+// of their parts, compressed layers must be plausible for their parts, and the config descriptor must match the
+// canonical JSON of the bottle.
+func ContextWithLayerConsistency(ctx context.Context) context.Context {
+	return context.WithValue(ctx, layerConsistencyKey{}, true)
+}
+
+// LayerConsistencyFromContext returns true if the layer consistency checks are enabled
+func LayerConsistencyFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(layerConsistencyKey{}).(bool)
+	return enabled
+}
+
 // ValidateManifest validates a bottle Manifest for correctness
 func ValidateManifest(m ocispec.Manifest) error {
 	return validation.ValidateStruct(&m,
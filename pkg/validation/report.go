@@ -0,0 +1,352 @@
+package validation
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	v1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Severity indicates how serious a validation issue is
+type Severity string
+
+const (
+	// SeverityError is used for issues that make the document invalid
+	SeverityError Severity = "error"
+
+	// SeverityWarning is used for issues that do not make the document invalid but should be fixed
+	SeverityWarning Severity = "warning"
+)
+
+// Stable, machine readable codes for issues found by the bottle validation.
+// Codes produced by ozzo-validation rules (e.g., "validation_required") are passed through unchanged.
+const (
+	// CodeInvalid is used when no more specific code is known
+	CodeInvalid = "validation_invalid"
+
+	// CodeInternal is used when the validation itself failed
+	CodeInternal = "validation_internal"
+
+	// CodeNotUnique is used when a name or path must be unique but is not
+	CodeNotUnique = "validation_not_unique"
+
+	// CodePartPrefix is used when a part name is a path prefix of another part name
+	CodePartPrefix = "validation_part_prefix"
+
+	// CodeLayerCount is used when the number of parts does not match the number of layers in the manifest
+	CodeLayerCount = "validation_layer_count"
+
+	// CodeTrailingSlash is used when a part name must have a trailing slash (archived layer)
+	CodeTrailingSlash = "validation_trailing_slash"
+
+	// CodeNoTrailingSlash is used when a part name must not have a trailing slash (non-archived layer)
+	CodeNoTrailingSlash = "validation_no_trailing_slash"
+
+	// CodeLayerDigest is used when the digest of an uncompressed layer is not the digest of its part
+	CodeLayerDigest = "validation_layer_digest"
+
+	// CodeLayerSize is used when the size of an uncompressed layer is not the size of its part
+	CodeLayerSize = "validation_layer_size"
+
+	// CodeLayerImplausible is used when a compressed layer is not plausible for its part
+	CodeLayerImplausible = "validation_layer_implausible"
+
+	// CodeConfigDigest is used when the config descriptor's digest is not the digest of the canonical bottle JSON
+	CodeConfigDigest = "validation_config_digest"
+
+	// CodeConfigSize is used when the config descriptor's size is not the size of the canonical bottle JSON
+	CodeConfigSize = "validation_config_size"
+
+	// CodeBlobMissing is used when a blob referenced by the manifest cannot be read
+	CodeBlobMissing = "validation_blob_missing"
+
+	// CodeBlobMismatch is used when the content of a blob does not match the digest or size of its descriptor
+	CodeBlobMismatch = "validation_blob_mismatch"
+
+	// CodeContentDigest is used when the (uncompressed) content of a part does not match the part's digest
+	CodeContentDigest = "validation_content_digest"
+
+	// CodeContentSize is used when the (uncompressed) content of a part does not match the part's size
+	CodeContentSize = "validation_content_size"
+
+	// CodeArtifactMissing is used when a public artifact cannot be found in its part
+	CodeArtifactMissing = "validation_artifact_missing"
+
+	// CodeArtifactDigest is used when the content of a public artifact does not match its digest
+	CodeArtifactDigest = "validation_artifact_digest"
+
+	// CodeArtifactTooLarge is used when a public artifact is not smaller than the size limit
+	CodeArtifactTooLarge = "validation_artifact_too_large"
+
+	// CodeArtifactNotInPart is used when a public artifact path is not within any part
+	CodeArtifactNotInPart = "validation_artifact_not_in_part"
+
+	// CodeArtifactInMultipleParts is used when a public artifact path is within more than one part
+	CodeArtifactInMultipleParts = "validation_artifact_in_multiple_parts"
+
+	// CodeInvalidLabel is used when a label key or value does not follow the Kubernetes conventions
+	CodeInvalidLabel = "validation_invalid_label"
+
+	// CodeInvalidAnnotation is used when an annotation does not follow the Kubernetes conventions
+	CodeInvalidAnnotation = "validation_invalid_annotation"
+)
+
+// Issue is a single problem found while validating a document
+type Issue struct {
+	// Field is the path to the offending field (e.g., "parts[3].name")
+	Field string `json:"field"`
+
+	// Code is a stable machine readable identifier for the kind of problem
+	Code string `json:"code"`
+
+	// Severity of the problem
+	Severity Severity `json:"severity"`
+
+	// Message is the human readable description of the problem
+	Message string `json:"message"`
+}
+
+// NewIssue returns an error level issue for the field
+func NewIssue(fldPath *field.Path, code, message string) Issue {
+	return Issue{
+		Field:    pathString(fldPath),
+		Code:     code,
+		Severity: SeverityError,
+		Message:  message,
+	}
+}
+
+// NewWarning returns a warning level issue for the field
+func NewWarning(fldPath *field.Path, code, message string) Issue {
+	issue := NewIssue(fldPath, code, message)
+	issue.Severity = SeverityWarning
+	return issue
+}
+
+// Error implements the error interface
+func (i Issue) Error() string {
+	if i.Field == "" {
+		return i.Message
+	}
+	return i.Field + ": " + i.Message
+}
+
+// JSONPointer returns the RFC 6901 JSON pointer to the offending field (e.g., "/parts/3/name")
+func (i Issue) JSONPointer() string {
+	var sb strings.Builder
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	rest := i.Field
+	for rest != "" {
+		var segment string
+		switch rest[0] {
+		case '[':
+			// map keys and indices are enclosed in brackets
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				end = len(rest)
+				rest += "]"
+			}
+			segment, rest = rest[1:end], rest[end+1:]
+		case '.':
+			rest = rest[1:]
+			continue
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment, rest = rest[:end], rest[end:]
+		}
+		sb.WriteString("/")
+		sb.WriteString(escaper.Replace(segment))
+	}
+	return sb.String()
+}
+
+// IssueList is a list of issues
+type IssueList []Issue
+
+// Err converts the list into a single error (or nil if there are no error level issues).
+// Only the messages are included since the caller (usually ozzo-validation) adds the field name.
+func (l IssueList) Err() error {
+	var msgs []string
+	for _, issue := range l {
+		if issue.Severity == SeverityError {
+			msgs = append(msgs, issue.Message)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// Report is the result of validating a document.  It lists every issue found instead of stopping at the first one.
+type Report struct {
+	// Issues are all the problems found, in the order they were found
+	Issues IssueList `json:"issues"`
+}
+
+// Add appends the issues to the report
+func (r *Report) Add(issues ...Issue) {
+	r.Issues = append(r.Issues, issues...)
+}
+
+// AddError flattens err into issues rooted at fldPath and adds them to the report.
+// It understands ozzo-validation errors, Kubernetes field errors and Issues.  Other errors are recorded as CodeInvalid.
+func (r *Report) AddError(fldPath *field.Path, err error) {
+	if err == nil {
+		return
+	}
+
+	var issue Issue
+	var errs validation.Errors
+	var internal validation.InternalError
+	var verr validation.Error
+	var ferr *field.Error
+	var agg utilerrors.Aggregate
+	switch {
+	case errors.As(err, &issue):
+		if issue.Field == "" {
+			issue.Field = pathString(fldPath)
+		}
+		r.Add(issue)
+	case errors.As(err, &errs):
+		// sort the keys for a deterministic report
+		keys := make([]string, 0, len(errs))
+		for k := range errs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r.AddError(childPath(fldPath, k), errs[k])
+		}
+	case errors.As(err, &internal):
+		r.Add(NewIssue(fldPath, CodeInternal, internal.InternalError().Error()))
+	case errors.As(err, &verr):
+		r.Add(NewIssue(fldPath, verr.Code(), verr.Error()))
+	case errors.As(err, &ferr):
+		r.Add(NewIssue(fldPath, fieldErrorCode(ferr.Type), ferr.ErrorBody()))
+	case errors.As(err, &agg):
+		for _, e := range agg.Errors() {
+			r.AddError(fldPath, e)
+		}
+	default:
+		r.Add(NewIssue(fldPath, CodeInvalid, err.Error()))
+	}
+}
+
+// HasErrors returns true if any issue has error severity
+func (r *Report) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the report as an error if it contains any error level issues, nil otherwise
+func (r *Report) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	return r
+}
+
+// Error implements the error interface
+func (r *Report) Error() string {
+	msgs := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		msgs[i] = issue.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// LabelIssues validates each label with the Kubernetes label rules and reports problems by key
+func LabelIssues(fldPath *field.Path, labels map[string]string) IssueList {
+	var issues IssueList
+	for _, k := range sortedKeys(labels) {
+		keyPath := fldPath.Key(k)
+		for _, e := range v1validation.ValidateLabels(map[string]string{k: labels[k]}, keyPath) {
+			issues = append(issues, NewIssue(keyPath, CodeInvalidLabel, e.ErrorBody()))
+		}
+	}
+	return issues
+}
+
+// AnnotationIssues validates the annotations with the Kubernetes annotation rules and reports problems by key
+func AnnotationIssues(fldPath *field.Path, annotations map[string]string) IssueList {
+	var issues IssueList
+	for _, k := range sortedKeys(annotations) {
+		keyPath := fldPath.Key(k)
+		// The rule is QualifiedName except that case doesn't matter (same as apivalidation.ValidateAnnotations)
+		for _, msg := range k8svalidation.IsQualifiedName(strings.ToLower(k)) {
+			issues = append(issues, NewIssue(keyPath, CodeInvalidAnnotation, field.Invalid(keyPath, k, msg).ErrorBody()))
+		}
+	}
+	// the total size limit is not a per key problem
+	if err := apivalidation.ValidateAnnotationsSize(annotations); err != nil {
+		issues = append(issues, NewIssue(fldPath, CodeInvalidAnnotation, err.Error()))
+	}
+	return issues
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// childPath extends the path with the key from an ozzo-validation error map.  Numeric keys are slice indices.
+func childPath(fldPath *field.Path, key string) *field.Path {
+	if i, err := strconv.Atoi(key); err == nil {
+		if fldPath == nil {
+			return field.NewPath("").Index(i)
+		}
+		return fldPath.Index(i)
+	}
+	if fldPath == nil {
+		return field.NewPath(key)
+	}
+	return fldPath.Child(key)
+}
+
+// pathString converts the path to a string (the nil path is the document root)
+func pathString(fldPath *field.Path) string {
+	if fldPath == nil {
+		return ""
+	}
+	return fldPath.String()
+}
+
+var fieldErrorCodes = map[field.ErrorType]string{
+	field.ErrorTypeNotFound:     "validation_not_found",
+	field.ErrorTypeRequired:     "validation_required",
+	field.ErrorTypeDuplicate:    CodeNotUnique,
+	field.ErrorTypeInvalid:      CodeInvalid,
+	field.ErrorTypeNotSupported: "validation_not_supported",
+	field.ErrorTypeForbidden:    "validation_forbidden",
+	field.ErrorTypeTooLong:      "validation_too_long",
+	field.ErrorTypeTooMany:      "validation_too_many",
+	field.ErrorTypeInternal:     CodeInternal,
+	field.ErrorTypeTypeInvalid:  "validation_type_invalid",
+}
+
+// fieldErrorCode maps the Kubernetes error types to our codes
+func fieldErrorCode(t field.ErrorType) string {
+	if code, ok := fieldErrorCodes[t]; ok {
+		return code
+	}
+	return CodeInvalid
+}
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestIssue_JSONPointer(t *testing.T) {
+	tests := []struct {
+		name  string
+		field string
+		want  string
+	}{
+		{"root", "", ""},
+		{"simple", "description", "/description"},
+		{"index", "parts[3].name", "/parts/3/name"},
+		{"map key", "labels[viewer.data.act3-ace.io/Jupyter-Base]", "/labels/viewer.data.act3-ace.io~1Jupyter-Base"},
+		{"nested", "parts[0].labels[a~b]", "/parts/0/labels/a~0b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Issue{Field: tt.field}.JSONPointer())
+		})
+	}
+}
+
+func TestReport_AddError(t *testing.T) {
+	assert := assert.New(t)
+
+	report := &Report{}
+	report.AddError(nil, validation.Errors{
+		"parts": validation.Errors{
+			"3": validation.Errors{
+				"name": validation.ErrRequired,
+			},
+			"1": errors.New("some problem"),
+		},
+		"kind": field.ErrorList{field.Invalid(field.NewPath("ignored"), "x", "bad kind")}.ToAggregate(),
+	})
+	report.AddError(field.NewPath("metrics").Index(0), NewIssue(nil, CodeNotUnique, "duplicate"))
+
+	assert.Equal(IssueList{
+		{Field: "kind", Code: CodeInvalid, Severity: SeverityError, Message: `Invalid value: "x": bad kind`},
+		{Field: "parts[1]", Code: CodeInvalid, Severity: SeverityError, Message: "some problem"},
+		{Field: "parts[3].name", Code: "validation_required", Severity: SeverityError, Message: "cannot be blank"},
+		{Field: "metrics[0]", Code: CodeNotUnique, Severity: SeverityError, Message: "duplicate"},
+	}, report.Issues)
+	assert.True(report.HasErrors())
+	assert.Error(report.Err())
+
+	out, err := json.Marshal(report)
+	assert.NoError(err)
+	assert.Contains(string(out), `{"field":"parts[3].name","code":"validation_required","severity":"error","message":"cannot be blank"}`)
+}
+
+func TestReport_Warnings(t *testing.T) {
+	assert := assert.New(t)
+
+	report := &Report{}
+	assert.NoError(report.Err())
+
+	report.Add(NewWarning(field.NewPath("description"), CodeInvalid, "should not be empty"))
+	assert.False(report.HasErrors())
+	assert.NoError(report.Err())
+	assert.NoError(report.Issues.Err())
+}
+
+func TestLabelIssues(t *testing.T) {
+	assert := assert.New(t)
+
+	issues := LabelIssues(field.NewPath("labels"), map[string]string{
+		"good":           "value",
+		"key with space": "werd?",
+	})
+	assert.Len(issues, 2)
+	for _, issue := range issues {
+		assert.Equal("labels[key with space]", issue.Field)
+		assert.Equal(CodeInvalidLabel, issue.Code)
+	}
+
+	issues = AnnotationIssues(field.NewPath("annotations"), map[string]string{
+		"bad key!": "anything goes here",
+	})
+	assert.Len(issues, 1)
+	assert.Equal("annotations[bad key!]", issues[0].Field)
+}
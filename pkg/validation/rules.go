@@ -75,12 +75,26 @@ var NoTrailingSlash = validation.By(checkNoTrailingSlash)
 
 type layerIndexKey struct{}
 
+// ContextWithLayerIndex sets the index of the layer (in the manifest in the context) that the part being validated
+// corresponds to.  It is needed by TrailingSlashInPart.
+//
+// Deprecated: the bottle validation checks the trailing slash of every part against the manifest in the context.
+func ContextWithLayerIndex(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, layerIndexKey{}, index)
+}
+
 func checkTrailingSlashWithContext(ctx context.Context, value any) error {
 	if manifest := ManifestFromContext(ctx); manifest != nil {
 		// find our layer
-		index := ctx.Value(layerIndexKey{})
-		i := index.(*int)
-		desc := manifest.Layers[*i]
+		i, ok := ctx.Value(layerIndexKey{}).(int)
+		if !ok || i < 0 || i >= len(manifest.Layers) {
+			return nil
+		}
+		desc := manifest.Layers[i]
+		if desc.MediaType == "" {
+			// reported by the manifest validation
+			return nil
+		}
 
 		if mediatype.IsArchived(desc.MediaType) {
 			// require a trailing slash
@@ -93,6 +107,9 @@ func checkTrailingSlashWithContext(ctx context.Context, value any) error {
 }
 
 // TrailingSlashInPart ensure the trailing slash is there iff the part is a archive part based on the manifest in the context
+// (the layer index must be set with ContextWithLayerIndex)
+//
+// Deprecated: the bottle validation checks the trailing slash of every part against the manifest in the context.
 var TrailingSlashInPart = validation.WithContext(checkTrailingSlashWithContext)
 
 // KubernetesLabels ensures that the labels keys and value conform to the Kubernetes rules for labels
@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/act3-ai/bottle-schema/pkg/mediatype"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrailingSlashInPart(t *testing.T) {
+	assert := assert.New(t)
+
+	manifest := &ocispecv1.Manifest{Layers: []ocispecv1.Descriptor{
+		{MediaType: mediatype.MediaTypeLayerTarZstd},
+		{MediaType: mediatype.MediaTypeLayer},
+		{},
+	}}
+	ctx := ContextWithManifest(context.Background(), manifest)
+	check := func(ctx context.Context, name string) error {
+		return validation.ValidateWithContext(ctx, name, TrailingSlashInPart)
+	}
+
+	assert.NoError(check(ContextWithLayerIndex(ctx, 0), "data/"))
+	assert.Error(check(ContextWithLayerIndex(ctx, 0), "data"))
+	assert.NoError(check(ContextWithLayerIndex(ctx, 1), "model.onnx"))
+	assert.Error(check(ContextWithLayerIndex(ctx, 1), "model.onnx/"))
+
+	// nothing to check against
+	assert.NoError(check(ContextWithLayerIndex(ctx, 2), "data"))
+	assert.NoError(check(ContextWithLayerIndex(ctx, 3), "data"))
+	assert.NoError(check(ctx, "data"))
+	assert.NoError(check(context.Background(), "data"))
+}